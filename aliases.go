@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+func runAliasesCommand(args []string) {
+	var flags Flags
+
+	fs := newCommandFlagSet("aliases", "<tag|hash>...", &flags)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	args = fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		eoe.ExitOnError(fmt.Errorf("require at least one tag or hash argument"), eoe.NewParams().WithExitCode(2))
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	repo, err := flags.repository()
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to get the repository"))
+
+	ctx := context.Background()
+
+	for _, arg := range args {
+		group, err := r.ResolveAliases(ctx, repo, arg)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to resolve aliases"))
+
+		if len(group.Tags) == 0 {
+			eoe.ExitOnError(fmt.Errorf("no tag points to %s", arg), eoeParams)
+		}
+
+		err = printAliasGroup(arg, *group, flags)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to print aliases"))
+	}
+
+	exitIfTagMoved(&flags)
+}
+
+func printAliasGroup(from string, group resolver.AliasGroup, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		for _, tag := range group.TagNames() {
+			fmt.Println(tag)
+		}
+
+	case "text":
+		fmt.Printf("%s: commitHash=%s, mostSpecificTag=%s, aliases=%s\n",
+			from,
+			group.CommitHash,
+			group.MostSpecificTag,
+			strings.Join(group.TagNames(), ", "))
+
+	case "json":
+		return printJSON(map[string]any{
+			"from":            from,
+			"commitHash":      group.CommitHash,
+			"mostSpecificTag": group.MostSpecificTag,
+			"aliases":         group.TagNames(),
+		})
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
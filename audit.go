@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/pin"
+)
+
+// exitCodeMutableRef is the exit code when any reference is not pinned to a commit hash at any depth
+const exitCodeMutableRef = 7
+
+func runAuditCommand(args []string) {
+	var flags Flags
+	var recursive bool
+	var maxDepth int
+
+	fs := newCommandFlagSet("audit", "[path...]", &flags)
+	fs.BoolVar(
+		&recursive,
+		"recursive",
+		false,
+		"follow the composite actions and the reusable workflows into the nested references at the pinned commits.",
+	)
+	fs.IntVar(
+		&maxDepth,
+		"max-depth",
+		10,
+		"maximum depth of the nested references to follow with --recursive.",
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	files, err := pin.FindWorkflowFiles(paths)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to find workflow files"))
+
+	auditor, err := pin.NewAuditor(&pin.AuditorParams{
+		Resolver:  r,
+		Recursive: recursive,
+		MaxDepth:  maxDepth,
+		Logger:    logger,
+	})
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to create an auditor"))
+
+	ctx := context.Background()
+	allNodes := []pin.AuditNode{}
+	var hasMutable, hasError bool
+
+	for _, file := range files {
+		nodes, err := auditor.AuditFile(ctx, file)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to audit a file"))
+
+		for _, node := range nodes {
+			hasMutable = hasMutable || node.HasMutable()
+			hasError = hasError || node.HasError()
+		}
+
+		allNodes = append(allNodes, nodes...)
+	}
+
+	err = printAuditNodes(allNodes, flags)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to print results"))
+
+	exitIfTagMoved(&flags)
+
+	if hasMutable {
+		os.Exit(exitCodeMutableRef)
+	}
+	if hasError {
+		os.Exit(1)
+	}
+}
+
+// printMutablePaths prints the chains of the references from the root to the mutable references and the errors
+func printMutablePaths(node pin.AuditNode, parents []string) {
+	label := node.Action
+	if node.Ref != "" {
+		label = fmt.Sprintf("%s@%s", node.Action, node.Ref)
+	}
+
+	chain := append(parents, label)
+	if len(parents) == 0 {
+		chain[0] = fmt.Sprintf("%s:%d: %s", node.Path, node.Line, chain[0])
+	}
+
+	if node.Mutable {
+		fmt.Printf("%s: mutable\n", strings.Join(chain, " > "))
+	}
+	if node.Error != "" {
+		fmt.Printf("%s: error: %s\n", strings.Join(chain, " > "), node.Error)
+	}
+
+	for _, child := range node.Children {
+		printMutablePaths(child, chain)
+	}
+}
+
+func auditNodeToMap(node pin.AuditNode) map[string]any {
+	children := make([]map[string]any, 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, auditNodeToMap(child))
+	}
+
+	body := map[string]any{
+		"path":       node.Path,
+		"line":       node.Line,
+		"action":     node.Action,
+		"ref":        node.Ref,
+		"commitHash": node.CommitHash,
+		"mutable":    node.Mutable,
+		"children":   children,
+	}
+	if node.Error != "" {
+		body["error"] = node.Error
+	}
+
+	return body
+}
+
+func printAuditNodes(nodes []pin.AuditNode, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		for _, node := range nodes {
+			printMutablePaths(node, nil)
+		}
+
+	case "text":
+		for _, node := range nodes {
+			fmt.Print(pin.FormatTree(node))
+		}
+
+	case "json":
+		bodies := make([]map[string]any, 0, len(nodes))
+		for _, node := range nodes {
+			bodies = append(bodies, auditNodeToMap(node))
+		}
+
+		return printJSON(bodies)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
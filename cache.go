@@ -0,0 +1,432 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/dustin/go-humanize"
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+var cacheSubcommands = []command{
+	{
+		name:    "stats",
+		summary: "show the statistics of the cache",
+		run:     runCacheStats,
+	},
+	{
+		name:    "list",
+		summary: "list the cached records of a repository",
+		run:     runCacheList,
+	},
+	{
+		name:    "prune",
+		summary: "delete expired records from the cache",
+		run:     runCachePrune,
+	},
+	{
+		name:    "clear",
+		summary: "delete the cached records of a repository",
+		run:     runCacheClear,
+	},
+	{
+		name:    "export",
+		summary: "export the cached records to a file",
+		run:     runCacheExport,
+	},
+	{
+		name:    "import",
+		summary: "import the cached records from files",
+		run:     runCacheImport,
+	},
+	{
+		name:    "path",
+		summary: "print the cache directory path",
+		run:     runCachePath,
+	},
+}
+
+func cacheUsage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n  gh taghash cache <subcommand> [flags]\n\nSubcommands:\n")
+	for _, c := range cacheSubcommands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.summary)
+	}
+}
+
+func runCacheCommand(args []string) {
+	if len(args) > 0 {
+		for _, c := range cacheSubcommands {
+			if c.name == args[0] {
+				c.run(args[1:])
+				return
+			}
+		}
+	}
+
+	cacheUsage()
+	os.Exit(2)
+}
+
+// parseThreshold parses a RFC3339 timestamp or a duration from now
+func parseThreshold(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid threshold (%s), expected a RFC3339 timestamp or a duration", value)
+	}
+
+	return now.Add(d), nil
+}
+
+func runCacheStats(args []string) {
+	var flags Flags
+
+	fs := newCommandFlagSet("cache stats", "", &flags)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	stats, err := r.CacheStats(context.Background())
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to get the cache statistics"))
+
+	switch flags.OutputFormat {
+	case "json":
+		err = printJSON(stats)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to print the cache statistics"))
+
+	default:
+		fmt.Printf("database: %s (%s)\n", stats.DBPath, humanize.Bytes(uint64(stats.DBSize)))
+		fmt.Printf("git cache: %s (%s)\n", stats.GitCacheDirPath, humanize.Bytes(uint64(stats.GitCacheSize)))
+		fmt.Printf("records: %d (expired: %d, soft-deleted: %d)\n", stats.RecordCount, stats.ExpiredCount, stats.SoftDeletedCount)
+		fmt.Printf("repositories: %d\n", len(stats.Repos))
+
+		for _, repoStats := range stats.Repos {
+			lastAccessedAt := "-"
+			if repoStats.LastAccessedAt != nil {
+				lastAccessedAt = repoStats.LastAccessedAt.Format(time.RFC3339)
+			}
+
+			fmt.Printf("  %s: records=%d, expired=%d, lastAccessedAt=%s\n",
+				repoStats.RepoID, repoStats.RecordCount, repoStats.ExpiredCount, lastAccessedAt)
+		}
+	}
+}
+
+func runCacheList(args []string) {
+	var flags Flags
+
+	fs := newCommandFlagSet("cache list", "", &flags)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	repo, err := flags.repository()
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to get the repository"))
+
+	gitTags, err := r.ListCache(context.Background(), repo)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to list the cache"))
+
+	switch flags.OutputFormat {
+	case "simple":
+		for _, gitTag := range gitTags {
+			fmt.Printf("%s %s %s\n", gitTag.Tag, gitTag.TagHash, gitTag.CommitHash)
+		}
+
+	case "text":
+		for _, gitTag := range gitTags {
+			fmt.Printf("%s: tagHash=%s, commitHash=%s, baseTag=%s, expiredAt=%s, upstreamDeletedAt=%s\n",
+				gitTag.Tag, gitTag.TagHash, gitTag.CommitHash, gitTag.BaseTag, gitTag.ExpiredAt.Format(time.RFC3339),
+				formatOptionalTime(gitTag.UpstreamDeletedAt))
+		}
+
+	case "json":
+		bodies := make([]map[string]string, 0, len(gitTags))
+		for _, gitTag := range gitTags {
+			body := map[string]string{
+				"tag":        gitTag.Tag,
+				"baseTag":    gitTag.BaseTag,
+				"tagHash":    gitTag.TagHash,
+				"commitHash": gitTag.CommitHash,
+				"expiredAt":  gitTag.ExpiredAt.Format(time.RFC3339),
+			}
+			if gitTag.UpstreamDeletedAt != nil {
+				body["upstreamDeletedAt"] = gitTag.UpstreamDeletedAt.Format(time.RFC3339)
+			}
+
+			bodies = append(bodies, body)
+		}
+
+		err = printJSON(bodies)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to print the cache"))
+	}
+}
+
+func runCachePrune(args []string) {
+	var flags Flags
+	var thresholdStr string
+
+	fs := newCommandFlagSet("cache prune", "", &flags)
+	fs.StringVar(
+		&thresholdStr,
+		"threshold",
+		"",
+		"delete records expired at the threshold. RFC3339 timestamp or a duration from now (e.g. 24h, -1h). If not specified, use the current time.",
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+	ctx := context.Background()
+
+	var threshold *time.Time
+	if thresholdStr != "" {
+		t, err := parseThreshold(thresholdStr, time.Now())
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to parse flags"))
+		threshold = &t
+	}
+
+	deletedCount, err := r.PruneCache(ctx, threshold)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to prune the cache"))
+
+	printDeletedCount(deletedCount, flags)
+}
+
+func runCacheClear(args []string) {
+	var flags Flags
+
+	fs := newCommandFlagSet("cache clear", "", &flags)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	repo, err := flags.repository()
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to get the repository"))
+
+	deletedCount, err := r.ClearRepoCache(context.Background(), repo)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to clear the cache"))
+
+	printDeletedCount(deletedCount, flags)
+}
+
+func runCachePath(args []string) {
+	var flags Flags
+
+	fs := newCommandFlagSet("cache path", "", &flags)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	_, r := setup(&flags)
+	defer r.Close()
+
+	switch flags.OutputFormat {
+	case "json":
+		err := printJSON(map[string]string{
+			"cacheDir":    r.CacheDirPath(),
+			"gitCacheDir": r.GitCacheDirPath(),
+		})
+		eoe.ExitOnError(err, eoe.NewParams().WithMessage("failed to print the cache path"))
+
+	default:
+		fmt.Println(r.CacheDirPath())
+	}
+}
+
+func printDeletedCount(deletedCount int64, flags Flags) {
+	switch flags.OutputFormat {
+	case "json":
+		err := printJSON(map[string]int64{
+			"deleted": deletedCount,
+		})
+		eoe.ExitOnError(err, eoe.NewParams().WithMessage("failed to print the result"))
+
+	case "simple":
+		fmt.Println(deletedCount)
+
+	default:
+		fmt.Printf("deleted %d records\n", deletedCount)
+	}
+}
+
+func readSigningKey(filePath string) ([]byte, error) {
+	if filePath == "" {
+		return nil, nil
+	}
+
+	key, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the signing key: %w", err)
+	}
+
+	key = bytes.TrimSpace(key)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("the signing key file is empty: %s", filePath)
+	}
+
+	return key, nil
+}
+
+func runCacheExport(args []string) {
+	var flags Flags
+	var outputPath, fileFormatStr, signingKeyPath string
+
+	fs := newCommandFlagSet("cache export", "[owner/repo...]", &flags)
+	fs.StringVarP(
+		&outputPath,
+		"output",
+		"o",
+		"-",
+		"output file path. '-' means the standard output.",
+	)
+	fs.StringVar(
+		&fileFormatStr,
+		"file-format",
+		string(resolver.ExportFormatJSON),
+		"export file format (json, ndjson)",
+	)
+	fs.StringVar(
+		&signingKeyPath,
+		"signing-key-file",
+		"",
+		"path to a file that contains a key to sign the exported records with HMAC-SHA256",
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	fileFormat, err := resolver.ParseExportFormat(fileFormatStr)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to parse flags"))
+
+	signingKey, err := readSigningKey(signingKeyPath)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to parse flags"))
+
+	repoIDs := fs.Args()
+	if len(repoIDs) == 0 && flags.RepoID != "" {
+		repoIDs = []string{flags.RepoID}
+	}
+
+	repos := make([]repository.Repository, 0, len(repoIDs))
+	for _, repoID := range repoIDs {
+		repo, err := repository.Parse(repoID)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to parse the repository ID"))
+
+		repos = append(repos, repo)
+	}
+
+	export, err := r.ExportCache(context.Background(), repos)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to export the cache"))
+
+	if signingKey != nil {
+		err = export.Sign(signingKey)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to sign the export"))
+	}
+
+	w := os.Stdout
+	if outputPath != "-" {
+		f, err := os.Create(outputPath)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to create the output file"))
+		defer f.Close()
+
+		w = f
+	}
+
+	err = export.Write(w, fileFormat)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to write the export"))
+
+	logger.Info("exported the cache", slog.Int("records", len(export.Records)), slog.String("output", outputPath))
+}
+
+func runCacheImport(args []string) {
+	var flags Flags
+	var signingKeyPath string
+
+	fs := newCommandFlagSet("cache import", "<file>...", &flags)
+	fs.StringVar(
+		&signingKeyPath,
+		"signing-key-file",
+		"",
+		"path to a file that contains a key to verify the HMAC-SHA256 signature. If specified, unsigned files are rejected.",
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	if fs.NArg() == 0 {
+		eoe.ExitOnError(errors.New("require at least one file argument"), eoe.NewParams().WithMessage("failed to parse flags"))
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	signingKey, err := readSigningKey(signingKeyPath)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to parse flags"))
+
+	total := resolver.ImportResult{}
+
+	for _, filePath := range fs.Args() {
+		export, err := readCacheExportFile(filePath)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to read an export"))
+
+		err = export.Verify(signingKey)
+		eoe.ExitOnError(err, eoeParams.WithMessage(fmt.Sprintf("failed to verify %s", filePath)))
+
+		result, err := r.ImportCache(context.Background(), export)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to import the cache"))
+
+		total.Created += result.Created
+		total.Updated += result.Updated
+		total.Skipped += result.Skipped
+	}
+
+	switch flags.OutputFormat {
+	case "json":
+		err = printJSON(total)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to print the result"))
+
+	default:
+		fmt.Printf("created=%d, updated=%d, skipped=%d\n", total.Created, total.Updated, total.Skipped)
+	}
+}
+
+func readCacheExportFile(filePath string) (*resolver.CacheExport, error) {
+	if filePath == "-" {
+		return resolver.ReadCacheExport(os.Stdin)
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	return resolver.ReadCacheExport(f)
+}
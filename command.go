@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// command represents a subcommand of the extension
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+var commands = []command{
+	{
+		name:    "cache",
+		summary: "inspect and maintain the cache",
+		run:     runCacheCommand,
+	},
+	{
+		name:    "warm",
+		summary: "populate the cache of repositories in advance",
+		run:     runWarmCommand,
+	},
+	{
+		name:    "history",
+		summary: "show the observed history of tags",
+		run:     runHistoryCommand,
+	},
+	{
+		name:    "aliases",
+		summary: "show the tags that point to the same commit",
+		run:     runAliasesCommand,
+	},
+	{
+		name:    "list",
+		summary: "list the tags of a repository with the hashes",
+		run:     runListCommand,
+	},
+	{
+		name:    "contains",
+		summary: "list the tags or the branches that contain a commit",
+		run:     runContainsCommand,
+	},
+	{
+		name:    "pin",
+		summary: "pin the actions, the pre-commit hooks and the Terraform modules to commit hashes",
+		run:     runPinCommand,
+	},
+	{
+		name:    "verify",
+		summary: "verify that the pinned actions match the version comments",
+		run:     runVerifyCommand,
+	},
+	{
+		name:    "verify-origin",
+		summary: "verify that commits are reachable from the branches or the tags of a repository",
+		run:     runVerifyOriginCommand,
+	},
+	{
+		name:    "outdated",
+		summary: "report the available updates of the pinned actions",
+		run:     runOutdatedCommand,
+	},
+	{
+		name:    "audit",
+		summary: "audit the actions in workflow files for mutable references",
+		run:     runAuditCommand,
+	},
+	{
+		name:    "gomod",
+		summary: "resolve the versions of Go modules hosted on GitHub to the tags and the commits",
+		run:     runGoModCommand,
+	},
+	{
+		name:    "submodules",
+		summary: "resolve the commits of the git submodules to the tags",
+		run:     runSubmodulesCommand,
+	},
+	{
+		name:    "rewrite",
+		summary: "pin the references in text files to commit hashes by regular expression rules",
+		run:     runRewriteCommand,
+	},
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+
+	return command{}, false
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n")
+	fmt.Fprintf(os.Stderr, "  gh taghash [flags] <tag|branch|ref|hash>...\n")
+	fmt.Fprintf(os.Stderr, "  gh taghash <command> [flags] [args]\n\n")
+
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.summary)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nFlags:\n")
+	pflag.PrintDefaults()
+}
+
+// newCommandFlagSet creates a flag set of a subcommand that has the common flags
+func newCommandFlagSet(name, argsUsage string, flags *Flags) *pflag.FlagSet {
+	fs := pflag.NewFlagSet(name, pflag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s\n\nFlags:\n", strings.TrimSpace("gh taghash "+name+" [flags] "+argsUsage))
+		fs.PrintDefaults()
+	}
+
+	addCommonFlags(fs, flags)
+
+	return fs
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/thombashi/eoe"
+)
+
+// exitCodeNotContained is the exit code when the tag does not contain the commit
+const exitCodeNotContained = 5
+
+func runContainsCommand(args []string) {
+	var flags Flags
+	var branches bool
+
+	fs := newCommandFlagSet("contains", "<commit> [tag]", &flags)
+	fs.BoolVar(
+		&branches,
+		"branches",
+		false,
+		"list the branches containing the commit instead of the tags.",
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	args = fs.Args()
+	if len(args) == 0 || len(args) > 2 {
+		fs.Usage()
+		eoe.ExitOnError(fmt.Errorf("require a commit and an optional tag argument"), eoe.NewParams().WithExitCode(2))
+	}
+	if branches && len(args) > 1 {
+		fs.Usage()
+		eoe.ExitOnError(fmt.Errorf("the --branches flag cannot be used with a tag argument"), eoe.NewParams().WithExitCode(2))
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	repo, err := flags.repository()
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to get the repository"))
+
+	ctx := context.Background()
+	commit := args[0]
+
+	if branches {
+		branchNames, err := r.BranchesContaining(ctx, repo, commit)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to list branches containing the commit"))
+
+		err = printContainingBranches(commit, branchNames, flags)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to print branches"))
+
+		return
+	}
+
+	if len(args) == 1 {
+		tags, err := r.TagsContaining(ctx, repo, commit)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to list tags containing the commit"))
+
+		err = printContainingTags(commit, tags, flags)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to print tags"))
+
+		return
+	}
+
+	tag := args[1]
+	contains, err := r.TagContains(ctx, repo, tag, commit)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to check whether the tag contains the commit"))
+
+	err = printTagContains(commit, tag, contains, flags)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to print the result"))
+
+	if !contains {
+		os.Exit(exitCodeNotContained)
+	}
+}
+
+func printContainingTags(commit string, tags []string, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		for _, tag := range tags {
+			fmt.Println(tag)
+		}
+
+	case "text":
+		fmt.Printf("%s: %s\n", commit, strings.Join(tags, ", "))
+
+	case "json":
+		return printJSON(map[string]any{
+			"commit": commit,
+			"tags":   tags,
+		})
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
+
+func printContainingBranches(commit string, branches []string, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		for _, branch := range branches {
+			fmt.Println(branch)
+		}
+
+	case "text":
+		fmt.Printf("%s: %s\n", commit, strings.Join(branches, ", "))
+
+	case "json":
+		return printJSON(map[string]any{
+			"commit":   commit,
+			"branches": branches,
+		})
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
+
+func printTagContains(commit, tag string, contains bool, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		fmt.Println(contains)
+
+	case "text":
+		fmt.Printf("%s contains %s: %t\n", tag, commit, contains)
+
+	case "json":
+		return printJSON(map[string]any{
+			"commit":   commit,
+			"tag":      tag,
+			"contains": contains,
+		})
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
@@ -4,12 +4,19 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/repository"
 	"github.com/spf13/pflag"
 	"github.com/thombashi/gh-taghash/pkg/resolver"
 )
 
+var validOutputFormats = []string{
+	"simple",
+	"text",
+	"json",
+}
+
 type Flags struct {
 	RepoID string
 
@@ -19,93 +26,234 @@ type Flags struct {
 	OutputFormat string
 	ShowBaseTag  bool
 
-	CacheDirPath string
-	CacheTTLStr  string
-	NoCache      bool
-}
+	CacheDirPath         string
+	CacheTTLStr          string
+	CacheMaxSizeStr      string
+	CacheVacuumThreshold int64
+	NoCache              bool
+	Refresh              bool
 
-func setFlags() (*Flags, []string, error) {
-	var flags Flags
-	validOutputFormats := []string{
-		"simple",
-		"text",
-		"json",
-	}
+	AsOfStr string
+	AsOf    *time.Time
 
-	pflag.StringVarP(
+	ExcludePrerelease bool
+
+	DescribeOptions resolver.DescribeOptions
+
+	FailOnTagMove bool
+}
+
+// addCommonFlags adds the flags that are shared by the root command and the subcommands
+func addCommonFlags(fs *pflag.FlagSet, flags *Flags) {
+	fs.StringVarP(
 		&flags.RepoID,
 		"repo",
 		"R",
 		"",
 		"GitHub repository ID. If not specified, use the current repository.",
 	)
-	pflag.StringVar(
+	fs.StringVar(
 		&flags.LogLevelStr,
 		"log-level",
 		"info",
 		"log level (debug, info, warn, error)",
 	)
-	pflag.StringVar(
+	fs.StringVar(
 		&flags.SqlLogLevelStr,
 		"sql-log-level",
 		"warn",
 		"SQL log level (silent, error, warn, info)",
 	)
 
-	pflag.StringVar(
+	fs.StringVar(
 		&flags.OutputFormat,
 		"format",
 		"simple",
 		fmt.Sprintf("output format (%s)", strings.Join(validOutputFormats, ", ")),
 	)
-	pflag.BoolVar(
-		&flags.ShowBaseTag,
-		"show-base-tag",
-		false,
-		"show the base tag when resolving a tag from a commit hash",
-	)
 
-	pflag.StringVar(
+	fs.StringVar(
 		&flags.CacheDirPath,
 		"cache-dir",
 		"",
 		"cache directory path. If not specified, use a user cache directory.",
 	)
-	pflag.StringVar(
+	fs.StringVar(
 		&flags.CacheTTLStr,
 		"cache-ttl",
 		"48h",
 		"base cache TTL (time-to-live)",
 	)
-	pflag.BoolVar(
-		&flags.NoCache,
-		"no-cache",
+	fs.StringVar(
+		&flags.CacheMaxSizeStr,
+		"cache-max-size",
+		"",
+		"maximum size of the cache database (e.g. 100MB). If exceeded, the least recently used repositories are evicted. If not specified, unlimited.",
+	)
+	fs.Int64Var(
+		&flags.CacheVacuumThreshold,
+		"cache-vacuum-threshold",
+		1000,
+		"number of deleted cache records to trigger compaction of the cache database. 0 disables compaction.",
+	)
+
+	fs.BoolVar(
+		&flags.FailOnTagMove,
+		"fail-on-tag-move",
 		false,
-		"disable cache",
+		fmt.Sprintf("exit with code %d if a tag is observed pointing to a different object from the last observation", exitCodeTagMoved),
 	)
+}
 
-	pflag.Parse()
+// normalize normalizes and validates the common flag values
+func (flags *Flags) normalize() error {
+	flags.SqlLogLevelStr = strings.ToLower(strings.TrimSpace(flags.SqlLogLevelStr))
+
+	flags.OutputFormat = strings.ToLower(strings.TrimSpace(flags.OutputFormat))
+	if !slices.Contains(validOutputFormats, flags.OutputFormat) {
+		return fmt.Errorf("invalid output format (%s), expected one of %s", flags.OutputFormat, strings.Join(validOutputFormats, ", "))
+	}
+
+	return nil
+}
 
+// repository returns the repository specified by the --repo flag.
+// If the flag is not specified, it returns the current repository.
+func (flags *Flags) repository() (repository.Repository, error) {
 	if flags.RepoID == "" {
 		repo, err := repository.Current()
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to get the current repository: %w", err)
+			return repository.Repository{}, fmt.Errorf("failed to get the current repository: %w", err)
 		}
 
 		flags.RepoID = resolver.ToRepoID(repo)
+
+		return repo, nil
 	}
 
-	flags.SqlLogLevelStr = strings.ToLower(strings.TrimSpace(flags.SqlLogLevelStr))
+	repo, err := repository.Parse(flags.RepoID)
+	if err != nil {
+		return repository.Repository{}, fmt.Errorf("failed to parse the repository ID: %w", err)
+	}
 
-	flags.OutputFormat = strings.ToLower(strings.TrimSpace(flags.OutputFormat))
-	if !slices.Contains(validOutputFormats, flags.OutputFormat) {
-		return nil, nil, fmt.Errorf("invalid output format (%s), expected one of %s", flags.OutputFormat, strings.Join(validOutputFormats, ", "))
+	return repo, nil
+}
+
+func setFlags() (*Flags, []string, error) {
+	var flags Flags
+
+	addCommonFlags(pflag.CommandLine, &flags)
+
+	pflag.BoolVar(
+		&flags.ShowBaseTag,
+		"show-base-tag",
+		false,
+		"show the base tag when resolving a tag from a commit hash",
+	)
+	pflag.BoolVar(
+		&flags.NoCache,
+		"no-cache",
+		false,
+		"disable cache",
+	)
+	pflag.BoolVar(
+		&flags.Refresh,
+		"refresh",
+		false,
+		"re-fetch the tags of the repository bypassing the HTTP cache before resolving. The cache of the other repositories is kept.",
+	)
+
+	pflag.BoolVar(
+		&flags.ExcludePrerelease,
+		"exclude-prerelease",
+		false,
+		"exclude pre-release versions when resolving a version range (e.g. ^4) or latest",
+	)
+	pflag.StringVar(
+		&flags.AsOfStr,
+		"as-of",
+		"",
+		"resolve tags and hashes as of the time (RFC3339 or YYYY-MM-DD) based on the observed tag history",
+	)
+
+	var describeAbbrev int
+	pflag.StringArrayVar(
+		&flags.DescribeOptions.Match,
+		"match",
+		nil,
+		"only consider tags matching the glob pattern when resolving a commit hash by git describe. also filters the tags exactly matching the hash. can be specified multiple times.",
+	)
+	pflag.StringArrayVar(
+		&flags.DescribeOptions.Exclude,
+		"exclude",
+		nil,
+		"do not consider tags matching the glob pattern when resolving a commit hash by git describe. also filters the tags exactly matching the hash. can be specified multiple times.",
+	)
+	pflag.BoolVar(
+		&flags.DescribeOptions.FirstParent,
+		"first-parent",
+		false,
+		"follow only the first parent of merge commits in git describe",
+	)
+	pflag.BoolVar(
+		&flags.DescribeOptions.Long,
+		"long",
+		false,
+		"always output the long format (tag-distance-gHASH) in git describe",
+	)
+	pflag.IntVar(
+		&describeAbbrev,
+		"abbrev",
+		7,
+		"number of hexadecimal digits of the abbreviated hash in git describe",
+	)
+	pflag.BoolVar(
+		&flags.DescribeOptions.Always,
+		"always",
+		false,
+		"output the abbreviated hash if no tag is found in git describe",
+	)
+
+	pflag.Parse()
+
+	if err := flags.normalize(); err != nil {
+		return nil, nil, err
+	}
+
+	if pflag.CommandLine.Changed("abbrev") {
+		flags.DescribeOptions.Abbrev = &describeAbbrev
+	}
+
+	if flags.AsOfStr != "" {
+		asOf, err := parseAsOf(flags.AsOfStr)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		flags.AsOf = &asOf
 	}
 
 	args := pflag.Args()
 	if len(args) == 0 {
-		return nil, nil, fmt.Errorf("require at least one tag or hash argument")
+		return nil, nil, fmt.Errorf("require at least one tag, ref or hash argument")
 	}
 
 	return &flags, args, nil
 }
+
+// parseAsOf parses a time in RFC3339 or a date in YYYY-MM-DD.
+// A date is treated as the end of the day in UTC.
+func parseAsOf(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --as-of value (%s), expected RFC3339 or YYYY-MM-DD", value)
+	}
+
+	return t.Add(24*time.Hour - time.Second), nil
+}
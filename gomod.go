@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// readModFileRequirements reads the requirements of a go.mod file. The replace directives are applied,
+// and the requirements replaced with local directories are skipped.
+func readModFileRequirements(path string) ([]module.Version, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	f, err := modfile.Parse(path, content, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	replaces := map[module.Version]module.Version{}
+	for _, replace := range f.Replace {
+		replaces[replace.Old] = replace.New
+	}
+
+	requirements := []module.Version{}
+	for _, require := range f.Require {
+		mod := require.Mod
+
+		if replaced, ok := replaces[mod]; ok {
+			mod = replaced
+		} else if replaced, ok := replaces[module.Version{Path: mod.Path}]; ok {
+			mod = replaced
+		}
+
+		if mod.Version == "" {
+			continue
+		}
+
+		requirements = append(requirements, mod)
+	}
+
+	return requirements, nil
+}
+
+// parseModuleVersion parses a "path@version" argument
+func parseModuleVersion(arg string) (module.Version, error) {
+	path, version, ok := strings.Cut(arg, "@")
+	if !ok || path == "" || version == "" {
+		return module.Version{}, fmt.Errorf("require a module version formatted as path@version: %s", arg)
+	}
+
+	return module.Version{Path: path, Version: version}, nil
+}
+
+func runGoModCommand(args []string) {
+	var flags Flags
+	var modFilePath string
+
+	fs := newCommandFlagSet("gomod", "[<path@version>...]", &flags)
+	fs.StringVar(
+		&modFilePath,
+		"modfile",
+		"go.mod",
+		"path to a go.mod file to resolve the requirements hosted on GitHub. used if no module version is specified.",
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	var modVersions []module.Version
+	if args = fs.Args(); len(args) > 0 {
+		for _, arg := range args {
+			modVersion, err := parseModuleVersion(arg)
+			eoe.ExitOnError(err, eoeParams.WithExitCode(2))
+
+			modVersions = append(modVersions, modVersion)
+		}
+	} else {
+		requirements, err := readModFileRequirements(modFilePath)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to read the requirements"))
+
+		for _, requirement := range requirements {
+			if _, _, err := resolver.ParseGoModulePath(requirement.Path); err != nil {
+				logger.Debug("skip a requirement", slog.String("module", requirement.String()), slog.String("reason", err.Error()))
+				continue
+			}
+
+			modVersions = append(modVersions, requirement)
+		}
+	}
+
+	ctx := context.Background()
+	var hasError bool
+
+	for _, modVersion := range modVersions {
+		result, err := r.ResolveGoModuleContext(ctx, modVersion.Path, modVersion.Version)
+		if err != nil {
+			logger.Error("failed to resolve a module version",
+				slog.String("module", modVersion.String()),
+				slog.String("error", err.Error()))
+			hasError = true
+			continue
+		}
+
+		err = printGoModuleVersion(*result, flags)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to print a result"))
+	}
+
+	exitIfTagMoved(&flags)
+
+	if hasError {
+		os.Exit(1)
+	}
+}
+
+func printGoModuleVersion(result resolver.GoModuleVersion, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		fmt.Println(result.CommitHash)
+
+	case "text":
+		nearestTag := result.NearestTag
+		if nearestTag == "" {
+			nearestTag = "(no tag)"
+		}
+
+		if result.Pseudo {
+			fmt.Printf("%s: %s (nearest tag: %s)\n", result, result.CommitHash, nearestTag)
+			return nil
+		}
+
+		fmt.Printf("%s: %s (%s)\n", result, result.CommitHash, result.Tag)
+
+	case "json":
+		return printJSON(map[string]any{
+			"module":     result.Path,
+			"version":    result.Version,
+			"repo":       result.RepoID,
+			"tag":        result.Tag,
+			"pseudo":     result.Pseudo,
+			"commitHash": result.CommitHash,
+			"nearestTag": result.NearestTag,
+		})
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
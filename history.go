@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+func runHistoryCommand(args []string) {
+	var flags Flags
+
+	fs := newCommandFlagSet("history", "<owner/repo> [tag]", &flags)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	// the repository can be specified by either of the --repo flag or the first argument
+	args = fs.Args()
+	if flags.RepoID == "" && len(args) > 0 {
+		flags.RepoID = args[0]
+		args = args[1:]
+	}
+	if flags.RepoID == "" || len(args) > 1 {
+		fs.Usage()
+		eoe.ExitOnError(fmt.Errorf("require a repository and an optional tag argument"), eoe.NewParams().WithExitCode(2))
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	repo, err := flags.repository()
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to get the repository"))
+
+	var tag string
+	if len(args) > 0 {
+		tag = args[0]
+	}
+
+	histories, err := r.TagHistory(context.Background(), repo, tag)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to get the tag history"))
+
+	err = printTagHistories(histories, flags)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to print the tag history"))
+}
+
+func printTagHistories(histories []resolver.GitTagHistory, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		for _, history := range histories {
+			fmt.Printf("%s %s %s %s %s %s\n",
+				history.Tag,
+				history.TagHash,
+				history.CommitHash,
+				history.FirstSeenAt.Format(time.RFC3339),
+				history.LastSeenAt.Format(time.RFC3339),
+				formatOptionalTime(history.UpstreamDeletedAt))
+		}
+
+	case "text":
+		for _, history := range histories {
+			fmt.Printf("%s: tagHash=%s, commitHash=%s, firstSeenAt=%s, lastSeenAt=%s, upstreamDeletedAt=%s\n",
+				history.Tag,
+				history.TagHash,
+				history.CommitHash,
+				history.FirstSeenAt.Format(time.RFC3339),
+				history.LastSeenAt.Format(time.RFC3339),
+				formatOptionalTime(history.UpstreamDeletedAt))
+		}
+
+	case "json":
+		bodies := make([]map[string]string, 0, len(histories))
+		for _, history := range histories {
+			body := map[string]string{
+				"repoId":      history.RepoID,
+				"tag":         history.Tag,
+				"tagHash":     history.TagHash,
+				"commitHash":  history.CommitHash,
+				"firstSeenAt": history.FirstSeenAt.Format(time.RFC3339),
+				"lastSeenAt":  history.LastSeenAt.Format(time.RFC3339),
+			}
+			if history.UpstreamDeletedAt != nil {
+				body["upstreamDeletedAt"] = history.UpstreamDeletedAt.Format(time.RFC3339)
+			}
+
+			bodies = append(bodies, body)
+		}
+
+		return printJSON(bodies)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
+
+// formatOptionalTime formats a time in RFC3339, or returns "-" if the time is nil
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+func runListCommand(args []string) {
+	var flags Flags
+	var glob, pattern, sortStr string
+	var limit int
+
+	fs := newCommandFlagSet("list", "[owner/repo]", &flags)
+	fs.StringVar(
+		&glob,
+		"glob",
+		"",
+		"glob pattern to filter tags (e.g. 'v4.*')",
+	)
+	fs.StringVar(
+		&pattern,
+		"regexp",
+		"",
+		"regular expression to filter tags",
+	)
+	fs.StringVar(
+		&sortStr,
+		"sort",
+		string(resolver.TagSortName),
+		"sort order of tags (name, semver, date). semver and date list newer tags first.",
+	)
+	fs.IntVar(
+		&limit,
+		"limit",
+		0,
+		"maximum number of tags to list. 0 means unlimited.",
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	// the repository can be specified by either of the --repo flag or the argument
+	args = fs.Args()
+	if flags.RepoID == "" && len(args) > 0 {
+		flags.RepoID = args[0]
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		fs.Usage()
+		eoe.ExitOnError(fmt.Errorf("require at most one repository argument"), eoe.NewParams().WithExitCode(2))
+	}
+
+	tagSort, err := resolver.ParseTagSort(sortStr)
+	eoe.ExitOnError(err, eoe.NewParams().WithMessage("failed to parse flags"))
+
+	params := &resolver.ListTagsParams{
+		Glob:  glob,
+		Sort:  tagSort,
+		Limit: limit,
+	}
+	if pattern != "" {
+		params.Regexp, err = regexp.Compile(pattern)
+		eoe.ExitOnError(err, eoe.NewParams().WithMessage("failed to parse a regular expression"))
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	repo, err := flags.repository()
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to get the repository"))
+
+	tagInfos, err := r.ListTags(context.Background(), repo, params)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to list tags"))
+
+	err = printTagInfos(tagInfos, flags)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to print tags"))
+
+	exitIfTagMoved(&flags)
+}
+
+func printTagInfos(tagInfos []resolver.TagInfo, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		for _, tagInfo := range tagInfos {
+			fmt.Printf("%s %s %s %s\n", tagInfo.Tag, tagInfo.TagHash, tagInfo.CommitHash, tagInfo.Type)
+		}
+
+	case "text":
+		for _, tagInfo := range tagInfos {
+			fmt.Printf("%s: tagHash=%s, commitHash=%s, type=%s, date=%s, aliases=%s\n",
+				tagInfo.Tag,
+				tagInfo.TagHash,
+				tagInfo.CommitHash,
+				tagInfo.Type,
+				formatOptionalTime(tagInfo.Date),
+				strings.Join(tagInfo.Aliases, ", "))
+		}
+
+	case "json":
+		bodies := make([]map[string]any, 0, len(tagInfos))
+		for _, tagInfo := range tagInfos {
+			body := map[string]any{
+				"tag":        tagInfo.Tag,
+				"tagHash":    tagInfo.TagHash,
+				"commitHash": tagInfo.CommitHash,
+				"type":       tagInfo.Type,
+				"aliases":    tagInfo.Aliases,
+			}
+			if tagInfo.Date != nil {
+				body["date"] = formatOptionalTime(tagInfo.Date)
+			}
+
+			bodies = append(bodies, body)
+		}
+
+		return printJSON(bodies)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
@@ -3,14 +3,17 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
-	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/dustin/go-humanize"
 	"github.com/phsym/console-slog"
+	"github.com/spf13/pflag"
 	"github.com/thombashi/eoe"
 	gitdescribe "github.com/thombashi/gh-git-describe/pkg/executor"
 	"github.com/thombashi/gh-taghash/pkg/resolver"
@@ -19,8 +22,26 @@ import (
 
 const (
 	jsonIndent = "    "
+
+	// exitCodeTagMoved is the exit code when a tag move is detected with the --fail-on-tag-move flag
+	exitCodeTagMoved = 3
+
+	// exitCodeTagDeleted is the exit code when a tag to resolve is deleted upstream
+	exitCodeTagDeleted = 4
+
+	statusDeletedUpstream = "deletedUpstream"
 )
 
+// tagMoveCount is the number of the tag moves detected by the resolver
+var tagMoveCount int
+
+// exitIfTagMoved exits the program if any tag move is detected and the --fail-on-tag-move flag is set
+func exitIfTagMoved(flags *Flags) {
+	if flags.FailOnTagMove && tagMoveCount > 0 {
+		os.Exit(exitCodeTagMoved)
+	}
+}
+
 func newLogger(level slog.Level) *slog.Logger {
 	logger := slog.New(
 		console.NewHandler(os.Stderr, &console.HandlerOptions{
@@ -52,6 +73,17 @@ func toGormLogLevel(logLevel string) (gormlogger.LogLevel, error) {
 	}
 }
 
+func printJSON(v any) error {
+	jsonData, err := json.MarshalIndent(v, "", jsonIndent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal a JSON: %w", err)
+	}
+
+	fmt.Println(string(jsonData))
+
+	return nil
+}
+
 func printTag(gitTag resolver.GitTag, flags Flags) error {
 	switch flags.OutputFormat {
 	case "simple", "text":
@@ -62,8 +94,16 @@ func printTag(gitTag resolver.GitTag, flags Flags) error {
 		}
 
 	case "json":
-		body := map[string]string{
-			"tag": gitTag.Tag,
+		describe := gitTag.Describe()
+		body := map[string]any{
+			"tag":        gitTag.Tag,
+			"baseTag":    describe.BaseTag,
+			"distance":   describe.Distance,
+			"exactMatch": describe.ExactMatch,
+		}
+
+		if describe.AbbrevHash != "" {
+			body["abbrevHash"] = describe.AbbrevHash
 		}
 
 		if flags.ShowBaseTag {
@@ -129,30 +169,153 @@ func printHashes(gitTag resolver.GitTag, flags Flags) error {
 	return nil
 }
 
-func main() {
-	var err error
+// printRef prints the hashes of a resolved ref. The JSON output includes the qualified name and the type of the ref.
+func printRef(resolvedRef resolver.ResolvedRef, flags Flags) error {
+	if flags.OutputFormat != "json" {
+		return printHashes(resolvedRef.ToGitTag(), flags)
+	}
 
-	flags, args, err := setFlags()
-	eoe.ExitOnError(err, eoe.NewParams().WithMessage("failed to set flags"))
+	return printJSON(map[string]string{
+		"ref":        resolvedRef.Ref,
+		"refType":    string(resolvedRef.Type),
+		"tagHash":    resolvedRef.TagHash,
+		"commitHash": resolvedRef.CommitHash,
+	})
+}
 
-	var logLevel slog.Level
-	err = logLevel.UnmarshalText([]byte(flags.LogLevelStr))
-	eoe.ExitOnError(err, eoe.NewParams().WithMessage("failed to get a slog level"))
+// printDeletedTag prints the status of a tag deleted upstream with the last known hashes
+func printDeletedTag(gitTag resolver.GitTag, flags Flags) error {
+	deletedAt := gitTag.UpstreamDeletedAt.Format(time.RFC3339)
 
-	logger := newLogger(logLevel)
-	eoeParams := eoe.NewParams().WithLogger(logger)
+	switch flags.OutputFormat {
+	case "simple":
+		// print nothing not to be mistaken for the resolved hashes
+
+	case "text":
+		fmt.Printf("%s: deleted upstream at %s (last known commitHash: %s)\n", gitTag.Tag, deletedAt, gitTag.CommitHash)
+
+	case "json":
+		return printJSON(map[string]string{
+			"tag":               gitTag.Tag,
+			"status":            statusDeletedUpstream,
+			"upstreamDeletedAt": deletedAt,
+			"tagHash":           gitTag.TagHash,
+			"commitHash":        gitTag.CommitHash,
+		})
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
+
+// printQueryResult prints the tag chosen for a version query with the hashes
+func printQueryResult(query string, gitTag resolver.GitTag, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		fmt.Println(gitTag.Tag)
+		return printHashes(gitTag, flags)
+
+	case "text":
+		fmt.Printf("%s: tag=%s, tagHash=%s, commitHash=%s\n", query, gitTag.Tag, gitTag.TagHash, gitTag.CommitHash)
+
+	case "json":
+		return printJSON(map[string]string{
+			"query":      query,
+			"tag":        gitTag.Tag,
+			"tagHash":    gitTag.TagHash,
+			"commitHash": gitTag.CommitHash,
+		})
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
+
+// printAsOfResult prints a result of a point-in-time resolution with the basis of the result
+func printAsOfResult(result resolver.AsOfResult, fromHash bool, flags Flags) error {
+	gitTag := result.GitTag
+
+	switch flags.OutputFormat {
+	case "simple":
+		// the basis is logged to stderr
+		if fromHash {
+			return printTag(gitTag, flags)
+		}
+
+		return printHashes(gitTag, flags)
+
+	case "text":
+		fmt.Printf("%s: tagHash=%s, commitHash=%s, asOf=%s, basis=%s (%s)\n",
+			gitTag.Tag,
+			gitTag.TagHash,
+			gitTag.CommitHash,
+			result.AsOf.Format(time.RFC3339),
+			result.Basis,
+			result.Reason)
+
+	case "json":
+		body := map[string]string{
+			"tag":        gitTag.Tag,
+			"tagHash":    gitTag.TagHash,
+			"commitHash": gitTag.CommitHash,
+			"asOf":       result.AsOf.Format(time.RFC3339),
+			"basis":      string(result.Basis),
+			"reason":     result.Reason,
+		}
+		if result.TaggedAt != nil {
+			body["taggedAt"] = result.TaggedAt.Format(time.RFC3339)
+		}
+		if result.CommittedAt != nil {
+			body["committedAt"] = result.CommittedAt.Format(time.RFC3339)
+		}
+
+		return printJSON(body)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
 
+// newResolver creates a resolver configured by the common flags
+func newResolver(flags *Flags, logger *slog.Logger) (*resolver.Resolver, error) {
 	cacheTTL, err := resolver.ParseCacheTTL(flags.CacheTTLStr)
-	eoe.ExitOnError(err, eoeParams.WithMessage("failed to parse a cache TTL"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse a cache TTL: %w", err)
+	}
 
 	if flags.NoCache {
 		cacheTTL.QueryTTL = 0
 	}
 
+	var cacheMaxSize uint64
+	if flags.CacheMaxSizeStr != "" {
+		cacheMaxSize, err = humanize.ParseBytes(flags.CacheMaxSizeStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse a cache max size: %w", err)
+		}
+	}
+
 	gqlClient, err := api.NewGraphQLClient(api.ClientOptions{
 		CacheTTL: cacheTTL.QueryTTL,
 	})
-	eoe.ExitOnError(err, eoeParams.WithMessage("failed to create a GitHub client"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a GitHub client: %w", err)
+	}
+
+	// a negative TTL makes every cached HTTP response stale:
+	// responses are always fetched from GitHub while the HTTP cache is updated for the subsequent queries.
+	refreshClient, err := api.NewGraphQLClient(api.ClientOptions{
+		CacheTTL: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a GitHub client: %w", err)
+	}
 
 	gdExecutor, err := gitdescribe.New(&gitdescribe.Params{
 		Logger:         logger,
@@ -160,29 +323,114 @@ func main() {
 		CacheDirPath:   flags.CacheDirPath,
 		CacheTTL:       cacheTTL.GitFileTTL,
 	})
-	eoe.ExitOnError(err, eoeParams.WithMessage("failed to create a git-describe executor"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a git-describe executor: %w", err)
+	}
 
 	gormLogLevel, err := toGormLogLevel(flags.SqlLogLevelStr)
-	eoe.ExitOnError(err, eoeParams.WithMessage("failed to get a GORM log level"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a GORM log level: %w", err)
+	}
 
 	r, err := resolver.New(&resolver.Params{
 		Client:          gqlClient,
+		RefreshClient:   refreshClient,
 		GitDescExecutor: gdExecutor,
 		Logger:          logger,
 		GormLogger:      resolver.NewGormLogger(gormLogLevel),
 		CacheDirPath:    flags.CacheDirPath,
 		ClearCache:      flags.NoCache,
 		CacheTTL:        *cacheTTL,
-		LogWithPackage:  true,
+		MaxCacheSize:    int64(cacheMaxSize),
+		VacuumThreshold: flags.CacheVacuumThreshold,
+		DescribeOptions: flags.DescribeOptions,
+		OnTagMove: func(move resolver.TagMove) {
+			tagMoveCount++
+		},
+		LogWithPackage: true,
 	})
-	eoe.ExitOnError(err, eoeParams.WithMessage("failed to create a resolver"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a resolver: %w", err)
+	}
+
+	return r, nil
+}
+
+// setup creates a logger and a resolver from the common flags
+func setup(flags *Flags) (*slog.Logger, *resolver.Resolver) {
+	var logLevel slog.Level
+	err := logLevel.UnmarshalText([]byte(flags.LogLevelStr))
+	eoe.ExitOnError(err, eoe.NewParams().WithMessage("failed to get a slog level"))
+
+	logger := newLogger(logLevel)
 
-	repo, err := repository.Parse(flags.RepoID)
-	eoe.ExitOnError(err, eoeParams.WithMessage("failed to parse the repository ID"))
+	r, err := newResolver(flags, logger)
+	eoe.ExitOnError(err, eoe.NewParams().WithLogger(logger).WithMessage("failed to set up"))
+
+	return logger, r
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		if command, ok := findCommand(os.Args[1]); ok {
+			command.run(os.Args[2:])
+			return
+		}
+	}
+
+	pflag.Usage = usage
+
+	flags, args, err := setFlags()
+	eoe.ExitOnError(err, eoe.NewParams().WithMessage("failed to set flags"))
+
+	logger, r := setup(flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	repo, err := flags.repository()
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to get the repository"))
 
 	ctx := context.Background()
 
+	if flags.Refresh {
+		err = r.Refresh(ctx, repo)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to refresh the repository"))
+	}
+
+	var hasDeletedTag bool
+
 	for _, arg := range args {
+		if flags.AsOf != nil {
+			results, err := r.ResolveAsOf(ctx, repo, arg, *flags.AsOf)
+			eoe.ExitOnError(err, eoeParams.WithMessage("failed to resolve as of the time"))
+
+			for _, result := range results {
+				logger.Info("resolved as of the time",
+					slog.String("from", arg),
+					slog.String("asOf", result.AsOf.Format(time.RFC3339)),
+					slog.String("basis", string(result.Basis)),
+					slog.String("reason", result.Reason))
+				err = printAsOfResult(result, resolver.IsSHA(arg), *flags)
+				eoe.ExitOnError(err, eoeParams.WithMessage("failed to print a result"))
+			}
+
+			continue
+		}
+
+		if resolver.IsVersionQuery(arg) {
+			gitTag, err := r.ResolveVersionQuery(ctx, repo, arg, &resolver.VersionQueryParams{
+				ExcludePrerelease: flags.ExcludePrerelease,
+			})
+			eoe.ExitOnError(err, eoeParams.WithMessage("failed to resolve a version query"))
+
+			logger.Debug("resolved a version query", slog.String("from", arg), slog.String("to", gitTag.String()))
+			err = printQueryResult(arg, *gitTag, *flags)
+			eoe.ExitOnError(err, eoeParams.WithMessage("failed to print a result"))
+
+			continue
+		}
+
 		if resolver.IsSHA(arg) {
 			hash := arg
 			gitTags, err := r.ResolveFromHashContext(ctx, repo, hash)
@@ -194,12 +442,33 @@ func main() {
 				eoe.ExitOnError(err, eoeParams.WithMessage("failed to print a tag"))
 			}
 		} else {
-			gitTag, err := r.ResolveFromTagContext(ctx, repo, arg)
-			eoe.ExitOnError(err, eoeParams.WithMessage("failed to resolve a tag"))
+			resolvedRef, err := r.ResolveRefContext(ctx, repo, arg)
+
+			var deletedErr *resolver.TagDeletedError
+			if errors.As(err, &deletedErr) {
+				logger.Warn("the tag is deleted upstream",
+					slog.String("tag", arg),
+					slog.String("deletedAt", deletedErr.GitTag.UpstreamDeletedAt.Format(time.RFC3339)))
+				err = printDeletedTag(deletedErr.GitTag, *flags)
+				eoe.ExitOnError(err, eoeParams.WithMessage("failed to print a tag"))
+
+				hasDeletedTag = true
+				continue
+			}
+			eoe.ExitOnError(err, eoeParams.WithMessage("failed to resolve a ref"))
 
-			logger.Debug("resolved a tag", slog.String("from", arg), slog.String("to", gitTag.String()))
-			err = printHashes(*gitTag, *flags)
+			logger.Debug("resolved a ref",
+				slog.String("from", arg),
+				slog.String("ref", resolvedRef.Ref),
+				slog.String("type", string(resolvedRef.Type)))
+			err = printRef(*resolvedRef, *flags)
 			eoe.ExitOnError(err, eoeParams.WithMessage("failed to print hashes"))
 		}
 	}
+
+	exitIfTagMoved(flags)
+
+	if hasDeletedTag {
+		os.Exit(exitCodeTagDeleted)
+	}
 }
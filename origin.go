@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+// exitCodeImpostorCommit is the exit code when a commit is not reachable from any branch or tag of the repository
+const exitCodeImpostorCommit = 8
+
+func runVerifyOriginCommand(args []string) {
+	var flags Flags
+
+	fs := newCommandFlagSet("verify-origin", "<hash>...", &flags)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	args = fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		eoe.ExitOnError(fmt.Errorf("require at least one hash argument"), eoe.NewParams().WithExitCode(2))
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	repo, err := flags.repository()
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to get the repository"))
+
+	ctx := context.Background()
+	var hasImpostor bool
+
+	for _, hash := range args {
+		origin, err := r.VerifyOriginContext(ctx, repo, hash)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to verify the origin of a commit"))
+
+		if !origin.Reachable {
+			logger.Warn("the commit is not reachable from any branch or tag of the repository",
+				slog.String("repo", origin.RepoID),
+				slog.String("commit", origin.CommitHash))
+			hasImpostor = true
+		}
+
+		err = printOrigin(*origin, flags)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to print a result"))
+	}
+
+	exitIfTagMoved(&flags)
+
+	if hasImpostor {
+		os.Exit(exitCodeImpostorCommit)
+	}
+}
+
+func printOrigin(origin resolver.Origin, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		fmt.Println(origin.Reachable)
+
+	case "text":
+		if !origin.Reachable {
+			fmt.Printf("%s: not reachable from any branch or tag of %s\n", origin.CommitHash, origin.RepoID)
+			return nil
+		}
+
+		fmt.Printf("%s: reachable from %s (%s)\n", origin.CommitHash, origin.Ref, origin.Source)
+
+	case "json":
+		body := map[string]any{
+			"repo":       origin.RepoID,
+			"commitHash": origin.CommitHash,
+			"reachable":  origin.Reachable,
+		}
+		if origin.Reachable {
+			body["ref"] = origin.Ref
+			body["source"] = string(origin.Source)
+		}
+
+		return printJSON(body)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
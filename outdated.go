@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/pin"
+)
+
+func runOutdatedCommand(args []string) {
+	var flags Flags
+	var allowMajor, apply bool
+
+	fs := newCommandFlagSet("outdated", "[path...]", &flags)
+	fs.BoolVar(
+		&allowMajor,
+		"major",
+		false,
+		"check the updates across the major versions. If not specified, check the latest version within the same major version.",
+	)
+	fs.BoolVar(
+		&apply,
+		"apply",
+		false,
+		"rewrite the outdated pins to the latest commit hashes and tags.",
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	files, err := pin.FindWorkflowFiles(paths)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to find workflow files"))
+
+	updater, err := pin.NewUpdater(&pin.UpdaterParams{
+		Resolver:   r,
+		AllowMajor: allowMajor,
+		Logger:     logger,
+	})
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to create an updater"))
+
+	ctx := context.Background()
+	allUpdates := []pin.Update{}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to read a file"))
+
+		updates, errs := updater.CheckContent(ctx, file, content)
+		for _, err := range errs {
+			logger.Warn("failed to check a reference", slog.String("error", err.Error()))
+		}
+
+		allUpdates = append(allUpdates, updates...)
+
+		if !apply {
+			continue
+		}
+
+		updated := pin.ApplyUpdates(file, content, updates)
+		if string(updated) == string(content) {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to get the file info"))
+
+		err = os.WriteFile(file, updated, info.Mode().Perm())
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to write a file"))
+	}
+
+	err = printUpdates(allUpdates, flags)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to print updates"))
+
+	exitIfTagMoved(&flags)
+}
+
+func printUpdates(updates []pin.Update, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		// print only the outdated references
+		for _, update := range updates {
+			if update.Outdated() {
+				fmt.Printf("%s:%d: %s %s -> %s (%s)\n",
+					update.Path, update.Line, update.Action, update.CurrentTag, update.LatestTag, update.LatestCommitHash)
+			}
+		}
+
+	case "text":
+		for _, update := range updates {
+			if !update.Outdated() {
+				fmt.Printf("%s:%d: %s %s: up to date\n", update.Path, update.Line, update.Action, update.CurrentTag)
+				continue
+			}
+
+			fmt.Printf("%s:%d: %s %s -> %s (%s)\n",
+				update.Path, update.Line, update.Action, update.CurrentTag, update.LatestTag, update.LatestCommitHash)
+		}
+
+	case "json":
+		bodies := make([]map[string]any, 0, len(updates))
+		for _, update := range updates {
+			bodies = append(bodies, map[string]any{
+				"path":             update.Path,
+				"line":             update.Line,
+				"action":           update.Action,
+				"commitHash":       update.Ref,
+				"currentTag":       update.CurrentTag,
+				"latestTag":        update.LatestTag,
+				"latestCommitHash": update.LatestCommitHash,
+				"outdated":         update.Outdated(),
+			})
+		}
+
+		return printJSON(bodies)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
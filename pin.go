@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/pin"
+)
+
+func runPinCommand(args []string) {
+	var flags Flags
+	var dryRun bool
+
+	fs := newCommandFlagSet("pin", "[path...]", &flags)
+	fs.BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"print the changes as a unified diff without rewriting the files. --format is ignored.",
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	files, err := pin.FindWorkflowFiles(paths)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to find workflow files"))
+
+	pinner, err := pin.New(&pin.Params{
+		Resolver: r,
+		Logger:   logger,
+	})
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to create a pinner"))
+
+	ctx := context.Background()
+	changes := []pin.Change{}
+	var hasError bool
+
+	for _, file := range files {
+		result, err := pinner.PinFile(ctx, file, dryRun)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to pin a file"))
+
+		for _, err := range result.Errors {
+			logger.Error("failed to resolve a reference", slog.String("error", err.Error()))
+			hasError = true
+		}
+
+		if dryRun {
+			diff, err := result.Diff()
+			eoe.ExitOnError(err, eoeParams.WithMessage("failed to make a diff"))
+
+			fmt.Print(diff)
+			continue
+		}
+
+		changes = append(changes, result.Changes...)
+	}
+
+	if !dryRun {
+		err = printPinChanges(changes, flags)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to print changes"))
+	}
+
+	exitIfTagMoved(&flags)
+
+	if hasError {
+		os.Exit(1)
+	}
+}
+
+func printPinChanges(changes []pin.Change, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple", "text":
+		for _, change := range changes {
+			fmt.Printf("%s -> %s\n", change.Reference, change.CommitHash)
+		}
+
+	case "json":
+		bodies := make([]map[string]any, 0, len(changes))
+		for _, change := range changes {
+			bodies = append(bodies, map[string]any{
+				"path":       change.Path,
+				"line":       change.Line,
+				"action":     change.Action,
+				"ref":        change.Ref,
+				"commitHash": change.CommitHash,
+			})
+		}
+
+		return printJSON(bodies)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
@@ -0,0 +1,320 @@
+package pin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+const defaultAuditMaxDepth = 10
+
+// localUsesRegexp matches a "uses:" line of a local action or a local reusable workflow (e.g. "./.github/actions/build").
+// The first group is the dash of a step, and the second group is the path without the leading "./".
+var localUsesRegexp = regexp.MustCompile(`^\s*(-\s+)?uses:\s*["']?\./([^"'\s#]+)["']?\s*(?:#.*)?$`)
+
+// AuditResolver resolves the refs of the action repositories and reads the files at the commits
+type AuditResolver interface {
+	RefResolver
+	ReadFileContext(ctx context.Context, repo repository.Repository, rev, path string) ([]byte, error)
+}
+
+// AuditorParams represents the parameters for NewAuditor
+type AuditorParams struct {
+	// Resolver is a resolver of the action repositories
+	Resolver AuditResolver
+
+	// Recursive is a flag to follow the composite actions and the reusable workflows into the nested references
+	Recursive bool
+
+	// MaxDepth is the maximum depth of the nested references to follow. Default is 10.
+	MaxDepth int
+
+	// Logger is a Logger used by the auditor
+	Logger *slog.Logger
+}
+
+// Auditor builds the dependency trees of the references to remote actions
+type Auditor struct {
+	resolver  AuditResolver
+	recursive bool
+	maxDepth  int
+	logger    *slog.Logger
+}
+
+// NewAuditor creates a new auditor
+func NewAuditor(params *AuditorParams) (*Auditor, error) {
+	if params.Resolver == nil {
+		return nil, errors.New("required a resolver")
+	}
+
+	logger := params.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	maxDepth := params.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultAuditMaxDepth
+	}
+
+	return &Auditor{
+		resolver:  params.Resolver,
+		recursive: params.Recursive,
+		maxDepth:  maxDepth,
+		logger:    logger,
+	}, nil
+}
+
+// AuditNode represents a reference in a dependency tree
+type AuditNode struct {
+	Reference
+
+	// CommitHash is the commit hash that the reference is resolved to.
+	// Empty if the reference failed to be resolved.
+	CommitHash string
+
+	// Mutable is true if the reference is not pinned to a commit hash
+	Mutable bool
+
+	// Error is the error message if the reference or the nested references failed to be read
+	Error string
+
+	// Children is the references used by the composite action or the reusable workflow.
+	// The local reusable workflows ("./path") called by the jobs are the references to the same repository
+	// at the same commit. The other local references are not followed.
+	Children []AuditNode
+}
+
+// HasMutable returns true if the reference or any nested reference is mutable
+func (n AuditNode) HasMutable() bool {
+	if n.Mutable {
+		return true
+	}
+
+	for _, child := range n.Children {
+		if child.HasMutable() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasError returns true if the reference or any nested reference failed to be audited
+func (n AuditNode) HasError() bool {
+	if n.Error != "" {
+		return true
+	}
+
+	for _, child := range n.Children {
+		if child.HasError() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isWorkflowFile returns true if a path is a workflow file rather than a directory of an action
+func isWorkflowFile(p string) bool {
+	ext := path.Ext(p)
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// metadataPaths returns the candidate paths of the file that defines the action or the reusable workflow
+func metadataPaths(ref Reference) []string {
+	if isWorkflowFile(ref.SubPath) {
+		// a reusable workflow
+		return []string{ref.SubPath}
+	}
+
+	return []string{path.Join(ref.SubPath, "action.yml"), path.Join(ref.SubPath, "action.yaml")}
+}
+
+// readMetadata reads the action metadata file or the reusable workflow file of a reference at a commit
+func (a *Auditor) readMetadata(ctx context.Context, ref Reference, commitHash string) (string, []byte, error) {
+	var errs []error
+
+	for _, p := range metadataPaths(ref) {
+		content, err := a.resolver.ReadFileContext(ctx, ref.Repository(), commitHash, p)
+		if err == nil {
+			return p, content, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	return "", nil, errors.Join(errs...)
+}
+
+// scanLocalReferences finds the local actions and the local reusable workflows used by the metadata file of
+// a reference. The reusable workflows called by the jobs of a reusable workflow are returned as the references
+// to the same repository at the same commit.
+// The others are resolved against the workspace of the caller rather than the repository of the reference,
+// so they are returned as the nodes with an error not to be followed.
+func scanLocalReferences(source string, content []byte, parent Reference, commitHash string) ([]Reference, []AuditNode) {
+	refs := []Reference{}
+	unfollowed := []AuditNode{}
+
+	for i, line := range splitLines(string(content)) {
+		body, _ := trimLineEnding(line)
+
+		matches := localUsesRegexp.FindStringSubmatch(body)
+		if matches == nil {
+			continue
+		}
+
+		subPath := path.Clean(matches[2])
+		if subPath == "." || subPath == ".." || strings.HasPrefix(subPath, "../") {
+			continue
+		}
+
+		isStep := matches[1] != ""
+		if isStep || !isWorkflowFile(parent.SubPath) || !isWorkflowFile(subPath) || path.Dir(subPath) != ".github/workflows" {
+			unfollowed = append(unfollowed, AuditNode{
+				Reference: Reference{
+					Path:    source,
+					Line:    i + 1,
+					Action:  "./" + subPath,
+					SubPath: subPath,
+				},
+				Error:    "a local action is resolved against the workspace of the caller",
+				Children: []AuditNode{},
+			})
+			continue
+		}
+
+		refs = append(refs, Reference{
+			Path:    source,
+			Line:    i + 1,
+			Action:  fmt.Sprintf("%s/%s/%s", parent.Owner, parent.Repo, subPath),
+			Owner:   parent.Owner,
+			Repo:    parent.Repo,
+			SubPath: subPath,
+			Ref:     commitHash,
+		})
+	}
+
+	return refs, unfollowed
+}
+
+// auditReference builds the dependency tree of a reference.
+// visited is the set of "owner/repo/path@commitHash" on the path from the root not to follow a cycle.
+func (a *Auditor) auditReference(ctx context.Context, ref Reference, depth int, visited map[string]bool) AuditNode {
+	node := AuditNode{
+		Reference: ref,
+		Mutable:   !ref.IsPinned(),
+		Children:  []AuditNode{},
+	}
+
+	if ref.IsPinned() {
+		node.CommitHash = ref.Ref
+	} else {
+		resolvedRef, err := a.resolver.ResolveRefContext(ctx, ref.Repository(), ref.Ref)
+		if err != nil {
+			node.Error = err.Error()
+			return node
+		}
+
+		node.CommitHash = resolvedRef.CommitHash
+	}
+
+	// the hook repositories of pre-commit and the Terraform modules do not have action metadata files
+	if !a.recursive || depth >= a.maxDepth || !ref.IsAction() {
+		return node
+	}
+
+	key := fmt.Sprintf("%s@%s", ref.Action, node.CommitHash)
+	if visited[key] {
+		return node
+	}
+
+	visited[key] = true
+	defer delete(visited, key)
+
+	metadataPath, content, err := a.readMetadata(ctx, ref, node.CommitHash)
+	if err != nil {
+		node.Error = err.Error()
+		return node
+	}
+
+	a.logger.Debug("auditing nested references", slog.String("ref", ref.String()), slog.String("path", metadataPath))
+
+	source := fmt.Sprintf("%s/%s@%s:%s", ref.Owner, ref.Repo, node.CommitHash, metadataPath)
+	localRefs, unfollowed := scanLocalReferences(source, content, ref, node.CommitHash)
+
+	for _, child := range append(Scan(source, content), localRefs...) {
+		node.Children = append(node.Children, a.auditReference(ctx, child, depth+1, visited))
+	}
+
+	node.Children = append(node.Children, unfollowed...)
+	sort.SliceStable(node.Children, func(i, j int) bool {
+		return node.Children[i].Line < node.Children[j].Line
+	})
+
+	return node
+}
+
+// AuditContent builds the dependency trees of the references to remote actions in a content
+func (a *Auditor) AuditContent(ctx context.Context, path string, content []byte) []AuditNode {
+	nodes := []AuditNode{}
+
+	for _, ref := range Scan(path, content) {
+		nodes = append(nodes, a.auditReference(ctx, ref, 0, map[string]bool{}))
+	}
+
+	return nodes
+}
+
+// AuditFile builds the dependency trees of the references to remote actions in a file
+func (a *Auditor) AuditFile(ctx context.Context, path string) ([]AuditNode, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return a.AuditContent(ctx, path, content), nil
+}
+
+// FormatTree formats a dependency tree with the indentation of the depth
+func FormatTree(node AuditNode) string {
+	var sb strings.Builder
+	formatTree(&sb, node, 0)
+
+	return sb.String()
+}
+
+func formatTree(sb *strings.Builder, node AuditNode, depth int) {
+	sb.WriteString(strings.Repeat("  ", depth))
+
+	if depth == 0 {
+		fmt.Fprintf(sb, "%s:%d: ", node.Path, node.Line)
+	}
+	sb.WriteString(node.Action)
+	if node.Ref != "" {
+		fmt.Fprintf(sb, "@%s", node.Ref)
+	}
+
+	if node.Mutable && node.CommitHash != "" {
+		fmt.Fprintf(sb, " (%s)", node.CommitHash)
+	}
+	if node.Mutable {
+		sb.WriteString(" [mutable]")
+	}
+	if node.Error != "" {
+		fmt.Fprintf(sb, " [error: %s]", node.Error)
+	}
+	sb.WriteString("\n")
+
+	for _, child := range node.Children {
+		formatTree(sb, child, depth+1)
+	}
+}
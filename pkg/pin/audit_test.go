@@ -0,0 +1,171 @@
+package pin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+// fakeAuditResolver resolves the refs and reads the files from maps
+type fakeAuditResolver struct {
+	// refs is a map of "owner/repo@ref" to commit hashes
+	refs map[string]string
+
+	// files is a map of "owner/repo@commitHash:path" to contents
+	files map[string]string
+}
+
+func (f fakeAuditResolver) ResolveRefContext(ctx context.Context, repo repository.Repository, ref string) (*resolver.ResolvedRef, error) {
+	hash, ok := f.refs[fmt.Sprintf("%s/%s@%s", repo.Owner, repo.Name, ref)]
+	if !ok {
+		return nil, fmt.Errorf("not found: %s/%s@%s", repo.Owner, repo.Name, ref)
+	}
+
+	return &resolver.ResolvedRef{Ref: "refs/tags/" + ref, Type: resolver.RefTypeTag, CommitHash: hash, TagHash: hash}, nil
+}
+
+func (f fakeAuditResolver) ReadFileContext(ctx context.Context, repo repository.Repository, rev, path string) ([]byte, error) {
+	content, ok := f.files[fmt.Sprintf("%s/%s@%s:%s", repo.Owner, repo.Name, rev, path)]
+	if !ok {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+
+	return []byte(content), nil
+}
+
+func TestAuditor_AuditContent(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	compositeHash := strings.Repeat("a", 40)
+	nestedHash := strings.Repeat("b", 40)
+	workflowHash := strings.Repeat("c", 40)
+	cycleHash := strings.Repeat("d", 40)
+
+	fake := fakeAuditResolver{
+		refs: map[string]string{
+			"other/action@v1": nestedHash,
+		},
+		files: map[string]string{
+			"owner/composite@" + compositeHash + ":action.yml": "runs:\n  using: composite\n  steps:\n    - uses: other/action@v1\n",
+			"other/action@" + nestedHash + ":action.yaml":      "runs:\n  using: node20\n",
+			"owner/workflows@" + workflowHash + ":.github/workflows/build.yml": "jobs:\n  build:\n    steps:\n" +
+				"      - uses: owner/composite@" + compositeHash + " # v2\n",
+			"owner/cycle@" + cycleHash + ":action.yml": "runs:\n  steps:\n    - uses: owner/cycle@" + cycleHash + "\n",
+		},
+	}
+
+	content := strings.Join([]string{
+		"jobs:",
+		"  build:",
+		"    uses: owner/workflows/.github/workflows/build.yml@" + workflowHash + " # v1",
+		"  test:",
+		"    steps:",
+		"      - uses: owner/cycle@" + cycleHash,
+		"      - uses: owner/missing@" + strings.Repeat("e", 40),
+		"",
+	}, "\n")
+
+	auditor, err := NewAuditor(&AuditorParams{Resolver: fake, Recursive: true})
+	r.NoError(err)
+
+	nodes := auditor.AuditContent(context.Background(), "ci.yml", []byte(content))
+	r.Len(nodes, 3)
+
+	workflow := nodes[0]
+	a.False(workflow.Mutable)
+	a.True(workflow.HasMutable())
+	r.Len(workflow.Children, 1)
+
+	composite := workflow.Children[0]
+	a.Equal("owner/composite", composite.Action)
+	a.False(composite.Mutable)
+	r.Len(composite.Children, 1)
+
+	nested := composite.Children[0]
+	a.True(nested.Mutable)
+	a.Equal(nestedHash, nested.CommitHash)
+	a.Empty(nested.Children)
+	a.Empty(nested.Error)
+
+	cycle := nodes[1]
+	r.Len(cycle.Children, 1)
+	a.Empty(cycle.Children[0].Children, "a cycle is not followed")
+	a.False(cycle.HasMutable())
+
+	a.True(nodes[2].HasError())
+
+	a.Equal(strings.Join([]string{
+		"ci.yml:3: owner/workflows/.github/workflows/build.yml@" + workflowHash,
+		"  owner/composite@" + compositeHash,
+		"    other/action@v1 (" + nestedHash + ") [mutable]",
+		"",
+	}, "\n"), FormatTree(workflow))
+
+	auditor, err = NewAuditor(&AuditorParams{Resolver: fake})
+	r.NoError(err)
+
+	nodes = auditor.AuditContent(context.Background(), "ci.yml", []byte(content))
+	r.Len(nodes, 3)
+	a.Empty(nodes[0].Children)
+	a.False(nodes[2].HasError(), "the files are not read without the recursive flag")
+}
+
+func TestAuditor_AuditContent_LocalReferences(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	workflowHash := strings.Repeat("c", 40)
+	compositeHash := strings.Repeat("d", 40)
+	nestedHash := strings.Repeat("b", 40)
+
+	fake := fakeAuditResolver{
+		refs: map[string]string{
+			"other/action@v1": nestedHash,
+		},
+		files: map[string]string{
+			"owner/workflows@" + workflowHash + ":.github/workflows/build.yml": "jobs:\n  build:\n    steps:\n" +
+				"      - uses: ./.github/actions/setup\n" +
+				"  lint:\n    uses: './.github/workflows/lint.yml'\n",
+			"owner/workflows@" + workflowHash + ":.github/workflows/lint.yml": "jobs:\n  lint:\n    steps:\n" +
+				"      - uses: other/action@v1\n" +
+				"      - uses: ./../outside\n",
+			"owner/composite@" + compositeHash + ":action.yml": "runs:\n  using: composite\n  steps:\n" +
+				"    - uses: ./.github/actions/setup\n",
+			"other/action@" + nestedHash + ":action.yml": "runs:\n  using: node20\n",
+		},
+	}
+
+	content := "jobs:\n  build:\n    uses: owner/workflows/.github/workflows/build.yml@" + workflowHash + "\n" +
+		"  test:\n    steps:\n      - uses: owner/composite@" + compositeHash + "\n"
+
+	auditor, err := NewAuditor(&AuditorParams{Resolver: fake, Recursive: true})
+	r.NoError(err)
+
+	nodes := auditor.AuditContent(context.Background(), "ci.yml", []byte(content))
+	r.Len(nodes, 2)
+
+	// only the reusable workflows called by the jobs are followed in the same repository at the same commit
+	errorMessage := "a local action is resolved against the workspace of the caller"
+	a.Equal(strings.Join([]string{
+		"ci.yml:3: owner/workflows/.github/workflows/build.yml@" + workflowHash,
+		"  ./.github/actions/setup [error: " + errorMessage + "]",
+		"  owner/workflows/.github/workflows/lint.yml@" + workflowHash,
+		"    other/action@v1 (" + nestedHash + ") [mutable]",
+		"",
+	}, "\n"), FormatTree(nodes[0]))
+
+	a.Equal(strings.Join([]string{
+		"ci.yml:6: owner/composite@" + compositeHash,
+		"  ./.github/actions/setup [error: " + errorMessage + "]",
+		"",
+	}, "\n"), FormatTree(nodes[1]))
+	a.True(nodes[1].HasError())
+	a.False(nodes[1].HasMutable())
+}
@@ -0,0 +1,3 @@
+// package pin provides a pinner that rewrites the action references of GitHub Actions workflow files,
+// the hook revisions of pre-commit config files and the module sources of Terraform files to commit hashes.
+package pin
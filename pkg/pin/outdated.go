@@ -0,0 +1,224 @@
+package pin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+// VersionResolver resolves a commit hash to the alias group and a version query to the tag.
+// Both use the cached tag list of a repository.
+type VersionResolver interface {
+	ResolveAliases(ctx context.Context, repo repository.Repository, tagOrHash string) (*resolver.AliasGroup, error)
+	ResolveVersionQuery(ctx context.Context, repo repository.Repository, query string, params *resolver.VersionQueryParams) (*resolver.GitTag, error)
+}
+
+// UpdaterParams represents the parameters for NewUpdater
+type UpdaterParams struct {
+	// Resolver is a resolver of the versions of the action repositories
+	Resolver VersionResolver
+
+	// AllowMajor is a flag to check the updates across the major versions.
+	// If false, the latest version within the same major version is checked.
+	AllowMajor bool
+
+	// Logger is a Logger used by the updater
+	Logger *slog.Logger
+}
+
+// Updater finds the updates of the references pinned to commit hashes
+type Updater struct {
+	resolver   VersionResolver
+	allowMajor bool
+	logger     *slog.Logger
+}
+
+// NewUpdater creates a new updater
+func NewUpdater(params *UpdaterParams) (*Updater, error) {
+	if params.Resolver == nil {
+		return nil, errors.New("required a resolver")
+	}
+
+	logger := params.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Updater{
+		resolver:   params.Resolver,
+		allowMajor: params.AllowMajor,
+		logger:     logger,
+	}, nil
+}
+
+// Update represents an available update of a reference pinned to a commit hash
+type Update struct {
+	Reference
+
+	// CurrentTag is the version tag that the pinned commit hash points to
+	CurrentTag string
+
+	// LatestTag is the latest version tag in the update scope
+	LatestTag string
+
+	// LatestCommitHash is the commit hash that the latest version tag points to
+	LatestCommitHash string
+}
+
+// Outdated returns true if a newer version is available
+func (u Update) Outdated() bool {
+	return u.LatestCommitHash != "" && u.LatestCommitHash != u.Ref
+}
+
+// currentVersion returns the version of a pinned commit hash: the most specific version tag that exactly matches
+// the hash, or the version comment if the hash is not tagged
+func currentVersion(group *resolver.AliasGroup, comment string) *resolver.Version {
+	var current *resolver.Version
+
+	for _, gitTag := range group.Tags {
+		if !gitTag.Describe().ExactMatch {
+			continue
+		}
+
+		v, err := resolver.ParseVersion(gitTag.Tag)
+		if err != nil {
+			continue
+		}
+
+		if current == nil || v.Parts > current.Parts {
+			current = v
+		}
+	}
+
+	if current != nil {
+		return current
+	}
+
+	if v, err := resolver.ParseVersion(versionOf(comment)); err == nil {
+		return v
+	}
+
+	return nil
+}
+
+// Check finds the latest version of a reference pinned to a commit hash
+func (u *Updater) Check(ctx context.Context, ref Reference) (*Update, error) {
+	if !ref.IsPinned() {
+		return nil, fmt.Errorf("%s: not pinned to a commit hash", ref)
+	}
+
+	repo := ref.Repository()
+
+	group, err := u.resolver.ResolveAliases(ctx, repo, ref.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+
+	current := currentVersion(group, ref.Comment)
+	if current == nil {
+		return nil, fmt.Errorf("%s: the pinned hash is not a version tag", ref)
+	}
+
+	query := current.Prefix + "latest"
+	if !u.allowMajor {
+		query = fmt.Sprintf("%s%d.x", current.Prefix, current.Major)
+	}
+
+	u.logger.Debug("checking the latest version",
+		slog.String("ref", ref.String()),
+		slog.String("current", current.Original),
+		slog.String("query", query))
+
+	latest, err := u.resolver.ResolveVersionQuery(ctx, repo, query, &resolver.VersionQueryParams{
+		ExcludePrerelease: !current.IsPrerelease(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+
+	update := &Update{
+		Reference:  ref,
+		CurrentTag: current.Original,
+		LatestTag:  current.Original,
+	}
+
+	latestVersion, err := resolver.ParseVersion(latest.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+
+	if latestVersion.Compare(*current) > 0 {
+		update.LatestTag = latest.Tag
+		update.LatestCommitHash = latest.CommitHash
+	}
+
+	return update, nil
+}
+
+// CheckContent finds the latest versions of the references pinned to commit hashes in a content.
+// The references that are not pinned are skipped.
+// The errors of the references that failed to be checked are returned with the updates of the others.
+func (u *Updater) CheckContent(ctx context.Context, path string, content []byte) ([]Update, []error) {
+	updates := []Update{}
+	var errs []error
+
+	for _, ref := range Scan(path, content) {
+		if !ref.IsPinned() {
+			continue
+		}
+
+		update, err := u.Check(ctx, ref)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		updates = append(updates, *update)
+	}
+
+	return updates, errs
+}
+
+// updateComment returns the trailing comment of an updated line:
+// the version of the comment is replaced with the latest tag and the rest of the comment is kept
+func updateComment(update Update) string {
+	prefix := ""
+	body := update.Comment
+	if strings.HasPrefix(body, frozenPrefix) {
+		prefix = frozenPrefix + " "
+		body = strings.TrimSpace(strings.TrimPrefix(body, frozenPrefix))
+	}
+
+	rest := strings.TrimSpace(strings.TrimPrefix(body, versionOf(body)))
+
+	comment := " # " + prefix + update.LatestTag
+	if rest != "" {
+		comment += " " + rest
+	}
+
+	return comment
+}
+
+// ApplyUpdates rewrites the outdated references in the content of a file to the latest commit hashes and tags
+func ApplyUpdates(path string, content []byte, updates []Update) []byte {
+	lineToUpdate := map[int]Update{}
+	for _, update := range updates {
+		if update.Outdated() {
+			lineToUpdate[update.Line] = update
+		}
+	}
+
+	return rewriteReferences(path, content, func(lineNum int, u refLine) (string, bool) {
+		update, ok := lineToUpdate[lineNum]
+		if !ok || update.Action != u.action || update.Ref != u.ref {
+			return "", false
+		}
+
+		return u.format(update.LatestCommitHash, updateComment(update)), true
+	})
+}
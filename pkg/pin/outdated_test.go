@@ -0,0 +1,109 @@
+package pin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+// fakeVersionResolver resolves the aliases and the version queries from a map of tags to commit hashes
+type fakeVersionResolver struct {
+	tagToHash map[string]string
+}
+
+func (f fakeVersionResolver) ResolveAliases(ctx context.Context, repo repository.Repository, tagOrHash string) (*resolver.AliasGroup, error) {
+	group := &resolver.AliasGroup{CommitHash: tagOrHash}
+	for tag, hash := range f.tagToHash {
+		if hash == tagOrHash {
+			group.Tags = append(group.Tags, resolver.GitTag{Tag: tag, BaseTag: tag, CommitHash: hash, TagHash: hash})
+		}
+	}
+
+	return group, nil
+}
+
+func (f fakeVersionResolver) ResolveVersionQuery(ctx context.Context, repo repository.Repository, query string, params *resolver.VersionQueryParams) (*resolver.GitTag, error) {
+	constraint, err := resolver.ParseVersionConstraint(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *resolver.Version
+	for tag := range f.tagToHash {
+		v, err := resolver.ParseVersion(tag)
+		if err != nil || v.Parts < 3 || (params.ExcludePrerelease && v.IsPrerelease()) || !constraint.Match(*v) {
+			continue
+		}
+
+		if best == nil || v.Compare(*best) > 0 {
+			best = v
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no tag satisfies the version query: %s", query)
+	}
+
+	return &resolver.GitTag{Tag: best.Original, CommitHash: f.tagToHash[best.Original]}, nil
+}
+
+func TestUpdater_CheckContent(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	v416 := strings.Repeat("a", 40)
+	v422 := strings.Repeat("b", 40)
+	v500 := strings.Repeat("c", 40)
+	rc := strings.Repeat("d", 40)
+	untagged := strings.Repeat("e", 40)
+
+	fake := fakeVersionResolver{tagToHash: map[string]string{
+		"v4.1.6":       v416,
+		"v4.2.2":       v422,
+		"v4":           v422,
+		"v5.0.0":       v500,
+		"v6.0.0-rc.1":  rc,
+		"not-a-semver": untagged,
+	}}
+
+	content := strings.Join([]string{
+		"steps:",
+		"  - uses: actions/checkout@" + v416 + " # v4.1.6 # checkout",
+		"  - uses: actions/checkout@" + v422 + " # v4",
+		"  - uses: actions/checkout@v4",
+		"  - uses: actions/checkout@" + untagged,
+		"",
+	}, "\n")
+
+	updater, err := NewUpdater(&UpdaterParams{Resolver: fake})
+	r.NoError(err)
+
+	updates, errs := updater.CheckContent(context.Background(), "ci.yml", []byte(content))
+	r.Len(updates, 2)
+	a.Len(errs, 1)
+
+	a.True(updates[0].Outdated())
+	a.Equal("v4.1.6", updates[0].CurrentTag)
+	a.Equal("v4.2.2", updates[0].LatestTag)
+	a.Equal(v422, updates[0].LatestCommitHash)
+
+	a.False(updates[1].Outdated())
+	a.Equal("v4.2.2", updates[1].CurrentTag)
+
+	want := strings.Replace(content, v416+" # v4.1.6 # checkout", v422+" # v4.2.2 # checkout", 1)
+	a.Equal(want, string(ApplyUpdates("ci.yml", []byte(content), updates)))
+
+	updater, err = NewUpdater(&UpdaterParams{Resolver: fake, AllowMajor: true})
+	r.NoError(err)
+
+	updates, _ = updater.CheckContent(context.Background(), "ci.yml", []byte(content))
+	r.Len(updates, 2)
+	a.Equal("v5.0.0", updates[0].LatestTag)
+	a.Equal("v5.0.0", updates[1].LatestTag)
+}
@@ -0,0 +1,215 @@
+package pin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+// RefResolver resolves a ref of a repository to the hashes
+type RefResolver interface {
+	ResolveRefContext(ctx context.Context, repo repository.Repository, ref string) (*resolver.ResolvedRef, error)
+}
+
+// Params represents the parameters for New
+type Params struct {
+	// Resolver is a resolver of the refs of the action repositories
+	Resolver RefResolver
+
+	// Logger is a Logger used by the pinner
+	Logger *slog.Logger
+}
+
+// Pinner rewrites the references to remote actions to the commit hashes
+type Pinner struct {
+	resolver RefResolver
+	logger   *slog.Logger
+
+	// resolved is the resolved refs per "owner/repo@ref" not to resolve the same ref twice
+	resolved map[string]*resolver.ResolvedRef
+}
+
+// New creates a new pinner
+func New(params *Params) (*Pinner, error) {
+	if params.Resolver == nil {
+		return nil, errors.New("required a resolver")
+	}
+
+	logger := params.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Pinner{
+		resolver: params.Resolver,
+		logger:   logger,
+		resolved: map[string]*resolver.ResolvedRef{},
+	}, nil
+}
+
+// Change represents a reference rewritten to a commit hash
+type Change struct {
+	Reference
+
+	// CommitHash is the commit hash that the reference is pinned to
+	CommitHash string
+}
+
+// Result represents the result of pinning a file
+type Result struct {
+	// Path is the path to the file
+	Path string
+
+	// Original is the original content of the file
+	Original []byte
+
+	// Pinned is the content of the file after pinning
+	Pinned []byte
+
+	// Changes is the references rewritten to the commit hashes
+	Changes []Change
+
+	// Errors is the errors of the references that failed to be resolved.
+	// The lines of the references are kept as they are.
+	Errors []error
+}
+
+// Changed returns true if the content of the file is changed
+func (r Result) Changed() bool {
+	return len(r.Changes) > 0
+}
+
+// Diff returns the unified diff of the file between before and after pinning
+func (r Result) Diff() (string, error) {
+	return Diff(r.Path, r.Original, r.Pinned)
+}
+
+// Diff returns the unified diff of a file between two contents
+func Diff(path string, before, after []byte) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: "a/" + path,
+		ToFile:   "b/" + path,
+		Context:  3,
+	})
+}
+
+func (p *Pinner) resolve(ctx context.Context, ref Reference) (*resolver.ResolvedRef, error) {
+	key := fmt.Sprintf("%s/%s@%s", ref.Owner, ref.Repo, ref.Ref)
+	if resolvedRef, ok := p.resolved[key]; ok {
+		return resolvedRef, nil
+	}
+
+	resolvedRef, err := p.resolver.ResolveRefContext(ctx, ref.Repository(), ref.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	p.resolved[key] = resolvedRef
+
+	return resolvedRef, nil
+}
+
+// trimVersionComment removes the version at the head of a comment (e.g. "v4" of "v4 # checkout"),
+// which is stale once the ref is pinned. The comment without a version is returned as it is.
+func trimVersionComment(comment string) string {
+	body := strings.TrimSpace(strings.TrimPrefix(comment, frozenPrefix))
+	version := versionOf(body)
+	if _, err := resolver.ParseVersion(version); err != nil {
+		return comment
+	}
+
+	rest := strings.TrimSpace(strings.TrimPrefix(body, version))
+
+	return strings.TrimSpace(strings.TrimPrefix(rest, "#"))
+}
+
+// pinComment returns the trailing comment of a pinned line: the original ref followed by the original comment.
+// A version at the head of the original comment is replaced with the ref rather than followed by it.
+// The ref of a pre-commit hook is written as "frozen: <ref>" as "pre-commit autoupdate --freeze" does.
+func pinComment(ref Reference) string {
+	comment := " # " + ref.Ref
+	if ref.IsPreCommitHook() {
+		comment = " # " + frozenPrefix + " " + ref.Ref
+	}
+	if rest := trimVersionComment(ref.Comment); rest != "" {
+		comment += " # " + rest
+	}
+
+	return comment
+}
+
+// PinContent rewrites the references to remote repositories in a content to "@<commitHash> # <ref>".
+// The "rev:" of a pre-commit config file is rewritten to "<commitHash> # frozen: <ref>".
+// The references already pinned to commit hashes are skipped.
+// The other lines, including the formatting and the comments, are kept as they are.
+func (p *Pinner) PinContent(ctx context.Context, path string, content []byte) *Result {
+	result := &Result{
+		Path:     path,
+		Original: content,
+		Changes:  []Change{},
+	}
+
+	result.Pinned = rewriteReferences(path, content, func(lineNum int, u refLine) (string, bool) {
+		ref := newReference(path, lineNum, u)
+		if ref.IsPinned() {
+			return "", false
+		}
+
+		resolvedRef, err := p.resolve(ctx, ref)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", ref, err))
+			return "", false
+		}
+
+		p.logger.Debug("pinning a reference", slog.String("ref", ref.String()), slog.String("commitHash", resolvedRef.CommitHash))
+
+		result.Changes = append(result.Changes, Change{Reference: ref, CommitHash: resolvedRef.CommitHash})
+
+		return u.format(resolvedRef.CommitHash, pinComment(ref)), true
+	})
+
+	return result
+}
+
+// PinFile rewrites the references to remote actions in a file.
+// The file is not written if dryRun is true.
+func (p *Pinner) PinFile(ctx context.Context, path string, dryRun bool) (*Result, error) {
+	return rewriteFile(path, dryRun, func(content []byte) *Result {
+		return p.PinContent(ctx, path, content)
+	})
+}
+
+// rewriteFile rewrites a file with the content of the result of a rewrite function.
+// The file permission is kept. The file is not written if dryRun is true.
+func rewriteFile(path string, dryRun bool, rewrite func(content []byte) *Result) (*Result, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the file info: %w", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	result := rewrite(content)
+
+	if dryRun || !result.Changed() {
+		return result, nil
+	}
+
+	if err := os.WriteFile(path, result.Pinned, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return result, nil
+}
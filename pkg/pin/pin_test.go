@@ -0,0 +1,178 @@
+package pin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+var (
+	checkoutHash = strings.Repeat("a", 40)
+	workflowHash = strings.Repeat("b", 40)
+)
+
+// fakeResolver resolves the refs from a map of "owner/repo@ref" to commit hashes
+type fakeResolver struct {
+	hashes map[string]string
+	calls  int
+}
+
+func (f *fakeResolver) ResolveRefContext(ctx context.Context, repo repository.Repository, ref string) (*resolver.ResolvedRef, error) {
+	f.calls++
+
+	hash, ok := f.hashes[fmt.Sprintf("%s/%s@%s", repo.Owner, repo.Name, ref)]
+	if !ok {
+		return nil, fmt.Errorf("not found: %s/%s@%s", repo.Owner, repo.Name, ref)
+	}
+
+	return &resolver.ResolvedRef{Ref: "refs/tags/" + ref, Type: resolver.RefTypeTag, CommitHash: hash, TagHash: hash}, nil
+}
+
+func newTestPinner(t *testing.T) (*Pinner, *fakeResolver) {
+	r := require.New(t)
+
+	fake := &fakeResolver{
+		hashes: map[string]string{
+			"actions/checkout@v4":     checkoutHash,
+			"owner/workflows@release": workflowHash,
+		},
+	}
+
+	pinner, err := New(&Params{Resolver: fake})
+	r.NoError(err)
+
+	return pinner, fake
+}
+
+const testWorkflow = `name: ci
+on: [push]
+
+jobs:
+  build:
+    # reusable workflow
+    uses: owner/workflows/.github/workflows/build.yml@release
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4 # checkout
+      - uses: "actions/checkout@v4"
+      - uses: ./local-action
+      - uses: docker://alpine:3.20
+      - uses: actions/setup-go@` + "0123456789abcdef0123456789abcdef01234567" + ` # v5
+      - uses: unknown/action@v1
+`
+
+func TestScan(t *testing.T) {
+	a := assert.New(t)
+
+	refs := Scan("ci.yml", []byte(testWorkflow))
+	a.Len(refs, 5)
+
+	a.Equal(Reference{
+		Path:    "ci.yml",
+		Line:    7,
+		Action:  "owner/workflows/.github/workflows/build.yml",
+		Owner:   "owner",
+		Repo:    "workflows",
+		SubPath: ".github/workflows/build.yml",
+		Ref:     "release",
+	}, refs[0])
+
+	a.Equal("checkout", refs[1].Comment)
+	a.False(refs[1].IsPinned())
+	a.True(refs[3].IsPinned())
+	a.Equal("v5", refs[3].Comment)
+}
+
+func TestPinner_PinContent(t *testing.T) {
+	a := assert.New(t)
+
+	pinner, fake := newTestPinner(t)
+
+	result := pinner.PinContent(context.Background(), "ci.yml", []byte(testWorkflow))
+	a.Len(result.Changes, 3)
+	a.Len(result.Errors, 1)
+	a.Equal(3, fake.calls, "the same ref is resolved once")
+
+	want := strings.NewReplacer(
+		"build.yml@release\n", "build.yml@"+workflowHash+" # release\n",
+		"actions/checkout@v4 # checkout\n", "actions/checkout@"+checkoutHash+" # v4 # checkout\n",
+		`"actions/checkout@v4"`, `"actions/checkout@`+checkoutHash+`" # v4`,
+	).Replace(testWorkflow)
+	a.Equal(want, string(result.Pinned))
+
+	// the line endings are kept
+	crlf := strings.ReplaceAll(testWorkflow, "\n", "\r\n")
+	result = pinner.PinContent(context.Background(), "ci.yml", []byte(crlf))
+	a.Equal(strings.ReplaceAll(want, "\n", "\r\n"), string(result.Pinned))
+
+	diff, err := result.Diff()
+	a.NoError(err)
+	a.Contains(diff, "--- a/ci.yml")
+	a.Contains(diff, "+      - uses: actions/checkout@"+checkoutHash+" # v4 # checkout")
+}
+
+func TestPinComment(t *testing.T) {
+	a := assert.New(t)
+
+	testCases := []struct {
+		ref  Reference
+		want string
+	}{
+		{Reference{Action: "actions/checkout", Ref: "v4"}, " # v4"},
+		{Reference{Action: "actions/checkout", Ref: "v4", Comment: "checkout"}, " # v4 # checkout"},
+		{Reference{Action: "actions/checkout", Ref: "v4.1.6", Comment: "v4"}, " # v4.1.6"},
+		{Reference{Action: "actions/checkout", Ref: "v4.1.6", Comment: "v4 # checkout"}, " # v4.1.6 # checkout"},
+		{Reference{Action: "actions/checkout", Ref: "main", Comment: "v4.1.6 latest"}, " # main # latest"},
+	}
+
+	for _, tc := range testCases {
+		a.Equal(tc.want, pinComment(tc.ref), tc.ref.Comment)
+	}
+}
+
+func TestPinner_PinFile(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	dir := t.TempDir()
+	workflowDir := filepath.Join(dir, ".github", "workflows")
+	r.NoError(os.MkdirAll(workflowDir, 0o755))
+	r.NoError(os.MkdirAll(filepath.Join(dir, "action"), 0o755))
+	r.NoError(os.MkdirAll(filepath.Join(dir, "node_modules", "x"), 0o755))
+
+	workflowPath := filepath.Join(workflowDir, "ci.yml")
+	r.NoError(os.WriteFile(workflowPath, []byte(testWorkflow), 0o644))
+	r.NoError(os.WriteFile(filepath.Join(workflowDir, "README.md"), []byte("uses: actions/checkout@v4\n"), 0o644))
+	r.NoError(os.WriteFile(filepath.Join(dir, "action", "action.yml"), []byte("runs:\n  using: composite\n"), 0o644))
+	r.NoError(os.WriteFile(filepath.Join(dir, "node_modules", "x", "action.yml"), []byte(""), 0o644))
+
+	files, err := FindWorkflowFiles([]string{dir})
+	r.NoError(err)
+	a.Equal([]string{filepath.Join(workflowDir, "ci.yml"), filepath.Join(dir, "action", "action.yml")}, files)
+
+	pinner, _ := newTestPinner(t)
+
+	result, err := pinner.PinFile(context.Background(), workflowPath, true)
+	r.NoError(err)
+	a.True(result.Changed())
+
+	content, err := os.ReadFile(workflowPath)
+	r.NoError(err)
+	a.Equal(testWorkflow, string(content), "the file is not written in a dry run")
+
+	result, err = pinner.PinFile(context.Background(), workflowPath, false)
+	r.NoError(err)
+
+	content, err = os.ReadFile(workflowPath)
+	r.NoError(err)
+	a.Equal(string(result.Pinned), string(content))
+}
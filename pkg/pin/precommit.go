@@ -0,0 +1,67 @@
+package pin
+
+import (
+	"path/filepath"
+	"regexp"
+)
+
+// frozenPrefix is the prefix of the version comment of a "rev:" frozen by "pre-commit autoupdate --freeze"
+const frozenPrefix = "frozen:"
+
+var (
+	// repoLineRegexp matches a "repo:" line of a pre-commit config file. The group is the repository URL.
+	repoLineRegexp = regexp.MustCompile(`^\s*(?:-\s+)?repo:\s*["']?([^"'\s#]+)["']?\s*(?:#.*)?$`)
+
+	// revLineRegexp matches a "rev:" line of a pre-commit config file.
+	// The groups are: the prefix up to the value, the opening quote, the rev, the closing quote,
+	// and the trailing spaces and comment.
+	revLineRegexp = regexp.MustCompile(`^(\s*(?:-\s+)?rev:\s*)(["']?)([^"'\s#]+)(["']?)(\s*(?:#.*)?)$`)
+
+	// githubRepoURLRegexp matches the URL of a GitHub repository. The groups are the owner and the name.
+	githubRepoURLRegexp = regexp.MustCompile(`^(?:https?://|ssh://git@|git@)github\.com[/:]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+)
+
+// IsPreCommitConfigFile returns true if a path is a pre-commit config file (.pre-commit-config.yaml)
+func IsPreCommitConfigFile(path string) bool {
+	base := filepath.Base(path)
+
+	return base == ".pre-commit-config.yaml" || base == ".pre-commit-config.yml"
+}
+
+// parseRepoURL returns the "owner/repo" of a GitHub repository URL.
+// It returns false for the other hosts and the special repositories of pre-commit ("local" and "meta").
+func parseRepoURL(url string) (string, bool) {
+	matches := githubRepoURLRegexp.FindStringSubmatch(url)
+	if matches == nil {
+		return "", false
+	}
+
+	return matches[1] + "/" + matches[2], true
+}
+
+// newPreCommitParser returns a parser for the lines of a pre-commit config file.
+// A "rev:" line is parsed as a reference to the repository of the preceding "repo:" line.
+func newPreCommitParser() lineParser {
+	var action string
+
+	return func(line string) (*refLine, bool) {
+		if matches := repoLineRegexp.FindStringSubmatch(line); matches != nil {
+			action, _ = parseRepoURL(matches[1])
+			return nil, false
+		}
+
+		matches := revLineRegexp.FindStringSubmatch(line)
+		if matches == nil || matches[2] != matches[4] || action == "" {
+			return nil, false
+		}
+
+		return &refLine{
+			prefix:   matches[1],
+			quote:    matches[2],
+			action:   action,
+			ref:      matches[3],
+			trailing: matches[5],
+			revOnly:  true,
+		}, true
+	}
+}
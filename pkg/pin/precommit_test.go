@@ -0,0 +1,110 @@
+package pin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPreCommitConfig = `repos:
+  - repo: https://github.com/pre-commit/pre-commit-hooks
+    rev: v4.6.0 # hooks
+    hooks:
+      - id: trailing-whitespace
+  - repo: git@github.com:psf/black.git
+    rev: "24.4.2"
+    hooks:
+      - id: black
+  - repo: https://gitlab.com/owner/hooks
+    rev: v1.0.0
+  - repo: local
+    hooks:
+      - id: lint
+`
+
+func TestIsPreCommitConfigFile(t *testing.T) {
+	a := assert.New(t)
+
+	a.True(IsPreCommitConfigFile(".pre-commit-config.yaml"))
+	a.True(IsPreCommitConfigFile("sub/.pre-commit-config.yml"))
+	a.False(IsPreCommitConfigFile(".pre-commit-hooks.yaml"))
+	a.False(IsPreCommitConfigFile(".github/workflows/ci.yml"))
+}
+
+func TestScan_PreCommitConfig(t *testing.T) {
+	a := assert.New(t)
+
+	refs := Scan(".pre-commit-config.yaml", []byte(testPreCommitConfig))
+	a.Len(refs, 2)
+
+	a.Equal(Reference{
+		Path:    ".pre-commit-config.yaml",
+		Line:    3,
+		Action:  "pre-commit/pre-commit-hooks",
+		Owner:   "pre-commit",
+		Repo:    "pre-commit-hooks",
+		Ref:     "v4.6.0",
+		Comment: "hooks",
+	}, refs[0])
+	a.True(refs[0].IsPreCommitHook())
+
+	a.Equal("psf/black", refs[1].Action)
+	a.Equal("24.4.2", refs[1].Ref)
+}
+
+func TestPinner_PinContent_PreCommitConfig(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	hooksHash := strings.Repeat("a", 40)
+	fake := &fakeResolver{hashes: map[string]string{"pre-commit/pre-commit-hooks@v4.6.0": hooksHash}}
+
+	pinner, err := New(&Params{Resolver: fake})
+	r.NoError(err)
+
+	result := pinner.PinContent(context.Background(), ".pre-commit-config.yaml", []byte(testPreCommitConfig))
+	r.Len(result.Changes, 1)
+	a.Len(result.Errors, 1)
+
+	want := strings.Replace(testPreCommitConfig, "rev: v4.6.0 # hooks", "rev: "+hooksHash+" # frozen: v4.6.0 # hooks", 1)
+	a.Equal(want, string(result.Pinned))
+}
+
+func TestVerifier_VerifyContent_PreCommitConfig(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	v460 := strings.Repeat("a", 40)
+	v450 := strings.Repeat("b", 40)
+
+	verifier, err := NewVerifier(&VerifierParams{
+		Resolver: fakeTagHashResolver{tagToHash: map[string]string{"v4.6.0": v460, "v4.5.0": v450}},
+	})
+	r.NoError(err)
+
+	content := strings.Join([]string{
+		"repos:",
+		"  - repo: https://github.com/pre-commit/pre-commit-hooks",
+		"    rev: " + v460 + "  # frozen: v4.6.0",
+		"  - repo: https://github.com/pre-commit/pre-commit-hooks",
+		"    rev: " + v450 + "  # frozen: v4.6.0",
+		"",
+	}, "\n")
+
+	results := verifier.VerifyContent(context.Background(), ".pre-commit-config.yaml", []byte(content))
+	r.Len(results, 2)
+
+	a.Equal(VerifyStatusOK, results[0].Status)
+	a.Equal("v4.6.0", results[0].Version)
+	a.Equal(VerifyStatusMismatch, results[1].Status)
+}
+
+func TestUpdateComment_Frozen(t *testing.T) {
+	a := assert.New(t)
+
+	update := Update{Reference: Reference{Comment: "frozen: v4.5.0 # hooks"}, LatestTag: "v4.6.0"}
+	a.Equal(" # frozen: v4.6.0 # hooks", updateComment(update))
+}
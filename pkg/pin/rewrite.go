@@ -0,0 +1,431 @@
+package pin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	groupRepo  = "repo"
+	groupOwner = "owner"
+	groupName  = "name"
+	groupRef   = "ref"
+)
+
+// Rule represents a rule to find the references to GitHub repositories in text files by a regular expression
+type Rule struct {
+	// Name is the name of the rule
+	Name string `yaml:"name"`
+
+	// Files is the glob patterns of the files that the rule applies to (e.g. "Dockerfile*", "requirements*.txt").
+	// A pattern without "/" matches the base name of a file, and a pattern with "/" matches the slash-separated path.
+	Files []string `yaml:"files"`
+
+	// Pattern is the regular expression that captures the repository and the ref with the named groups:
+	// "repo" ("owner/name") or "owner" and "name", and "ref"
+	Pattern string `yaml:"pattern"`
+
+	// Template is the text/template of the text that replaces the whole match. The data is TemplateData.
+	// If empty, only the "ref" group is replaced with the commit hash.
+	Template string `yaml:"template"`
+
+	pattern  *regexp.Regexp
+	template *template.Template
+}
+
+// TemplateData represents the data of a rule template
+type TemplateData struct {
+	// Repo is the repository formatted as "owner/name"
+	Repo string
+
+	Owner string
+	Name  string
+
+	// Ref is the original ref (e.g. "v1")
+	Ref string
+
+	// CommitHash is the commit hash that the ref points to
+	CommitHash string
+
+	// TagHash is the hash of the tag object. The same as CommitHash for a lightweight tag or a branch.
+	TagHash string
+
+	// Tag is the tag name that the ref is resolved to (e.g. "v1" of "refs/tags/v1").
+	// Empty if the ref is not resolved to a tag, such as a branch.
+	Tag string
+
+	// Groups is the named groups of the match
+	Groups map[string]string
+}
+
+type ruleFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// compile compiles the pattern and the template of a rule
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return errors.New("require a rule name")
+	}
+
+	if len(r.Files) == 0 {
+		return fmt.Errorf("%s: require file patterns", r.Name)
+	}
+
+	for _, pattern := range r.Files {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s: invalid file pattern: %s: %w", r.Name, pattern, err)
+		}
+	}
+
+	pattern, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("%s: invalid pattern: %w", r.Name, err)
+	}
+
+	groups := map[string]bool{}
+	for _, name := range pattern.SubexpNames() {
+		groups[name] = true
+	}
+
+	if !groups[groupRef] {
+		return fmt.Errorf("%s: the pattern requires a %q group", r.Name, groupRef)
+	}
+
+	if !groups[groupRepo] && !(groups[groupOwner] && groups[groupName]) {
+		return fmt.Errorf("%s: the pattern requires a %q group or %q and %q groups", r.Name, groupRepo, groupOwner, groupName)
+	}
+
+	r.pattern = pattern
+
+	if r.Template != "" {
+		tmpl, err := template.New(r.Name).Option("missingkey=error").Parse(r.Template)
+		if err != nil {
+			return fmt.Errorf("%s: invalid template: %w", r.Name, err)
+		}
+
+		r.template = tmpl
+	}
+
+	return nil
+}
+
+// MatchFile returns true if the rule applies to a file
+func (r Rule) MatchFile(path string) bool {
+	slashPath := filepath.ToSlash(filepath.Clean(path))
+
+	for _, pattern := range r.Files {
+		name := filepath.Base(path)
+		if strings.Contains(pattern, "/") {
+			name = slashPath
+		}
+
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseRules parses the rules of a YAML content:
+//
+//	rules:
+//	  - name: pip
+//	    files: ["requirements*.txt"]
+//	    pattern: 'git\+https://github\.com/(?P<repo>[\w.-]+/[\w.-]+?)(?:\.git)?@(?P<ref>[\w./-]+)'
+func ParseRules(content []byte) ([]Rule, error) {
+	var f ruleFile
+	if err := yaml.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse the rules: %w", err)
+	}
+
+	if len(f.Rules) == 0 {
+		return nil, errors.New("no rule is defined")
+	}
+
+	for i := range f.Rules {
+		if err := f.Rules[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return f.Rules, nil
+}
+
+// LoadRules reads the rules from a YAML file
+func LoadRules(path string) ([]Rule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return ParseRules(content)
+}
+
+// ruleMatch represents a match of a rule in a content
+type ruleMatch struct {
+	// start and end are the offsets of the whole match
+	start int
+	end   int
+
+	// refStart and refEnd are the offsets of the "ref" group
+	refStart int
+	refEnd   int
+
+	ref    Reference
+	groups map[string]string
+
+	// rule is the rule that finds the match
+	rule Rule
+}
+
+// overlaps returns true if the ranges of the matches overlap
+func (m ruleMatch) overlaps(other ruleMatch) bool {
+	return m.start < other.end && other.start < m.end
+}
+
+// findMatches finds the references of a rule in a content. The matches without the repository or the ref are skipped.
+func (r Rule) findMatches(path string, content []byte) []ruleMatch {
+	matches := []ruleMatch{}
+	names := r.pattern.SubexpNames()
+
+	for _, loc := range r.pattern.FindAllSubmatchIndex(content, -1) {
+		m := ruleMatch{start: loc[0], end: loc[1], groups: map[string]string{}, rule: r}
+
+		for i, name := range names {
+			if name == "" || loc[2*i] < 0 {
+				continue
+			}
+
+			m.groups[name] = string(content[loc[2*i]:loc[2*i+1]])
+			if name == groupRef {
+				m.refStart, m.refEnd = loc[2*i], loc[2*i+1]
+			}
+		}
+
+		repo := m.groups[groupRepo]
+		if repo == "" && m.groups[groupOwner] != "" && m.groups[groupName] != "" {
+			repo = m.groups[groupOwner] + "/" + m.groups[groupName]
+		}
+
+		owner, name, ok := strings.Cut(strings.TrimSuffix(repo, ".git"), "/")
+		if !ok || owner == "" || name == "" || m.groups[groupRef] == "" {
+			continue
+		}
+
+		m.ref = Reference{
+			Path:   path,
+			Line:   bytes.Count(content[:loc[0]], []byte("\n")) + 1,
+			Action: owner + "/" + name,
+			Owner:  owner,
+			Repo:   name,
+			Ref:    m.groups[groupRef],
+		}
+
+		matches = append(matches, m)
+	}
+
+	return matches
+}
+
+// RewriterParams represents the parameters for NewRewriter
+type RewriterParams struct {
+	// Resolver is a resolver of the refs of the repositories
+	Resolver RefResolver
+
+	// Rules is the rules to find the references
+	Rules []Rule
+
+	// Logger is a Logger used by the rewriter
+	Logger *slog.Logger
+}
+
+// Rewriter rewrites the references to GitHub repositories in text files to the commit hashes by the rules
+type Rewriter struct {
+	pinner *Pinner
+	rules  []Rule
+	logger *slog.Logger
+}
+
+// NewRewriter creates a new rewriter
+func NewRewriter(params *RewriterParams) (*Rewriter, error) {
+	if len(params.Rules) == 0 {
+		return nil, errors.New("required at least one rule")
+	}
+
+	pinner, err := New(&Params{Resolver: params.Resolver, Logger: params.Logger})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rewriter{
+		pinner: pinner,
+		rules:  params.Rules,
+		logger: pinner.logger,
+	}, nil
+}
+
+// FindFiles finds the files that any rule applies to under the paths. A path to a file is returned as it is.
+func (rw *Rewriter) FindFiles(paths []string) ([]string, error) {
+	return findFiles(paths, func(path string) bool {
+		for _, rule := range rw.rules {
+			if rule.MatchFile(path) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// ScanContent finds the references in a content by the rules that apply to the file.
+// The first rule wins if the matches overlap.
+func (rw *Rewriter) ScanContent(path string, content []byte) []Reference {
+	refs := []Reference{}
+
+	for _, m := range rw.findAllMatches(path, content) {
+		refs = append(refs, m.ref)
+	}
+
+	return refs
+}
+
+// replacement returns the text that replaces a match and the offsets of the replaced range
+func (r Rule) replacement(m ruleMatch, resolvedRef *resolver.ResolvedRef) (string, int, int, error) {
+	if r.template == nil {
+		return resolvedRef.CommitHash, m.refStart, m.refEnd, nil
+	}
+
+	var tag string
+	if resolvedRef.Type == resolver.RefTypeTag {
+		tag = resolvedRef.ToGitTag().Tag
+	}
+
+	var sb strings.Builder
+	err := r.template.Execute(&sb, TemplateData{
+		Repo:       m.ref.Action,
+		Owner:      m.ref.Owner,
+		Name:       m.ref.Repo,
+		Ref:        m.ref.Ref,
+		CommitHash: resolvedRef.CommitHash,
+		TagHash:    resolvedRef.TagHash,
+		Tag:        tag,
+		Groups:     m.groups,
+	})
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to execute the template of %s: %w", r.Name, err)
+	}
+
+	return sb.String(), m.start, m.end, nil
+}
+
+// findOverlap returns the match that overlaps with a match
+func findOverlap(matches []ruleMatch, m ruleMatch) (ruleMatch, bool) {
+	for _, other := range matches {
+		if m.overlaps(other) {
+			return other, true
+		}
+	}
+
+	return ruleMatch{}, false
+}
+
+// findAllMatches finds the matches of the rules that apply to the file in a content.
+// A match that overlaps with a match of a preceding rule is skipped.
+// The matches are returned in the order of the offsets.
+func (rw *Rewriter) findAllMatches(path string, content []byte) []ruleMatch {
+	matches := []ruleMatch{}
+
+	for _, rule := range rw.rules {
+		if !rule.MatchFile(path) {
+			continue
+		}
+
+		for _, m := range rule.findMatches(path, content) {
+			if preceding, ok := findOverlap(matches, m); ok {
+				rw.logger.Debug("skip a match overlapping with a preceding rule",
+					slog.String("rule", rule.Name),
+					slog.String("ref", m.ref.String()),
+					slog.String("precedingRule", preceding.rule.Name))
+				continue
+			}
+
+			matches = append(matches, m)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].start < matches[j].start
+	})
+
+	return matches
+}
+
+// RewriteContent rewrites the references in a content by the rules that apply to the file.
+// The references already pinned to commit hashes are skipped.
+// All the rules find the references in the original content, and the first rule wins if the matches overlap.
+func (rw *Rewriter) RewriteContent(ctx context.Context, path string, content []byte) *Result {
+	result := &Result{
+		Path:     path,
+		Original: content,
+		Changes:  []Change{},
+	}
+
+	var buf bytes.Buffer
+	last := 0
+
+	for _, m := range rw.findAllMatches(path, content) {
+		if m.ref.IsPinned() {
+			continue
+		}
+
+		resolvedRef, err := rw.pinner.resolve(ctx, m.ref)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", m.ref, err))
+			continue
+		}
+
+		text, start, end, err := m.rule.replacement(m, resolvedRef)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", m.ref, err))
+			continue
+		}
+
+		rw.logger.Debug("rewriting a reference",
+			slog.String("rule", m.rule.Name),
+			slog.String("ref", m.ref.String()),
+			slog.String("commitHash", resolvedRef.CommitHash))
+
+		buf.Write(content[last:start])
+		buf.WriteString(text)
+		last = end
+
+		result.Changes = append(result.Changes, Change{Reference: m.ref, CommitHash: resolvedRef.CommitHash})
+	}
+
+	buf.Write(content[last:])
+	result.Pinned = buf.Bytes()
+
+	return result
+}
+
+// RewriteFile rewrites the references in a file by the rules that apply to the file.
+// The file is not written if dryRun is true.
+func (rw *Rewriter) RewriteFile(ctx context.Context, path string, dryRun bool) (*Result, error) {
+	return rewriteFile(path, dryRun, func(content []byte) *Result {
+		return rw.RewriteContent(ctx, path, content)
+	})
+}
@@ -0,0 +1,149 @@
+package pin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `rules:
+  - name: pip
+    files: ["requirements*.txt"]
+    pattern: 'git\+https://github\.com/(?P<repo>[\w.-]+/[\w.-]+?)(?:\.git)?@(?P<ref>[\w./-]+)'
+  - name: dockerfile
+    files: ["Dockerfile", "docker/*.Dockerfile"]
+    pattern: 'ARG (?P<arg>\w+)_REF=(?P<ref>\S+) # github\.com/(?P<owner>[\w.-]+)/(?P<name>[\w.-]+)'
+    template: 'ARG {{.Groups.arg}}_REF={{.CommitHash}} # github.com/{{.Repo}} {{.Ref}}'
+`
+
+func TestParseRules(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	rules, err := ParseRules([]byte(testRules))
+	r.NoError(err)
+	r.Len(rules, 2)
+
+	a.True(rules[0].MatchFile("requirements-dev.txt"))
+	a.True(rules[0].MatchFile("sub/requirements.txt"))
+	a.False(rules[0].MatchFile("setup.py"))
+	a.True(rules[1].MatchFile("Dockerfile"))
+	a.True(rules[1].MatchFile("docker/app.Dockerfile"))
+	a.False(rules[1].MatchFile("other/app.Dockerfile"))
+
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "no rules", content: "rules: []"},
+		{name: "no ref group", content: "rules:\n  - {name: a, files: ['*'], pattern: '(?P<repo>.+)'}"},
+		{name: "no repo group", content: "rules:\n  - {name: a, files: ['*'], pattern: '(?P<owner>.+)@(?P<ref>.+)'}"},
+		{name: "no files", content: "rules:\n  - {name: a, pattern: '(?P<repo>.+)@(?P<ref>.+)'}"},
+		{name: "invalid pattern", content: "rules:\n  - {name: a, files: ['*'], pattern: '(?P<repo>'}"},
+		{name: "invalid template", content: "rules:\n  - {name: a, files: ['*'], pattern: '(?P<repo>.+)@(?P<ref>.+)', template: '{{'}"},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseRules([]byte(tc.content))
+		a.Error(err, tc.name)
+	}
+}
+
+func TestRewriter_RewriteContent(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	rules, err := ParseRules([]byte(testRules))
+	r.NoError(err)
+
+	fake := &fakeResolver{hashes: map[string]string{
+		"owner/lib@v1":      checkoutHash,
+		"owner/tool@v2.0.0": workflowHash,
+	}}
+
+	rewriter, err := NewRewriter(&RewriterParams{Resolver: fake, Rules: rules})
+	r.NoError(err)
+
+	requirements := strings.Join([]string{
+		"requests==2.32.3",
+		"lib @ git+https://github.com/owner/lib.git@v1",
+		"pinned @ git+https://github.com/owner/lib@" + checkoutHash,
+		"unknown @ git+https://github.com/owner/unknown@v1",
+		"",
+	}, "\n")
+
+	refs := rewriter.ScanContent("requirements.txt", []byte(requirements))
+	r.Len(refs, 3)
+	a.Equal("owner/lib", refs[0].Action)
+	a.Equal(2, refs[0].Line)
+	a.True(refs[1].IsPinned())
+
+	result := rewriter.RewriteContent(ctx, "requirements.txt", []byte(requirements))
+	r.Len(result.Changes, 1)
+	a.Len(result.Errors, 1)
+	a.Equal(strings.Replace(requirements, "lib.git@v1", "lib.git@"+checkoutHash, 1), string(result.Pinned))
+
+	dockerfile := "FROM alpine\nARG TOOL_REF=v2.0.0 # github.com/owner/tool\n"
+	result = rewriter.RewriteContent(ctx, "Dockerfile", []byte(dockerfile))
+	r.Len(result.Changes, 1)
+	a.Equal("FROM alpine\nARG TOOL_REF="+workflowHash+" # github.com/owner/tool v2.0.0\n", string(result.Pinned))
+
+	// the rules do not apply to the other files
+	result = rewriter.RewriteContent(ctx, "Makefile", []byte(dockerfile))
+	a.False(result.Changed())
+}
+
+func TestRewriter_RewriteContent_Overlap(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	rules, err := ParseRules([]byte(`rules:
+  - name: archive
+    files: ["install.sh"]
+    pattern: 'https://github\.com/(?P<repo>[\w.-]+/[\w.-]+)/archive/(?P<ref>[\w.-]+?)\.tar\.gz'
+    template: 'https://github.com/{{.Repo}}/archive/{{.CommitHash}}.tar.gz # github.com/{{.Repo}}@{{.Tag}}'
+  - name: at
+    files: ["install.sh"]
+    pattern: 'github\.com/(?P<repo>[\w.-]+/[\w.-]+)@(?P<ref>[\w.-]+)'
+  - name: bare
+    files: ["install.sh"]
+    pattern: '(?P<repo>owner/lib)@(?P<ref>v1)'
+    template: 'BARE'
+`))
+	r.NoError(err)
+
+	fake := &fakeResolver{hashes: map[string]string{
+		"owner/lib@v1":      checkoutHash,
+		"owner/tool@v2.0.0": workflowHash,
+	}}
+
+	rewriter, err := NewRewriter(&RewriterParams{Resolver: fake, Rules: rules})
+	r.NoError(err)
+
+	content := strings.Join([]string{
+		"curl -L https://github.com/owner/tool/archive/v2.0.0.tar.gz",
+		"pip install git+https://github.com/owner/lib@v1",
+		"",
+	}, "\n")
+
+	// the match of the "bare" rule overlaps with the match of the "at" rule
+	refs := rewriter.ScanContent("install.sh", []byte(content))
+	r.Len(refs, 2)
+
+	// the output of a template is not rewritten by the following rules
+	result := rewriter.RewriteContent(ctx, "install.sh", []byte(content))
+	a.Empty(result.Errors)
+	r.Len(result.Changes, 2)
+	a.Equal(1, result.Changes[0].Line)
+	a.Equal(2, result.Changes[1].Line)
+	a.Equal(strings.Join([]string{
+		"curl -L https://github.com/owner/tool/archive/" + workflowHash + ".tar.gz # github.com/owner/tool@v2.0.0",
+		"pip install git+https://github.com/owner/lib@" + checkoutHash,
+		"",
+	}, "\n"), string(result.Pinned))
+}
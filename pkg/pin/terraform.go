@@ -0,0 +1,44 @@
+package pin
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// terraformSourceRegexp matches a "source" line of a Terraform module that refers to a GitHub repository
+// with "?ref=" (e.g. source = "git::https://github.com/owner/repo.git//path?ref=v1.2.3").
+// The groups are: the prefix up to the ref, the owner, the repository name, the path in the repository,
+// the ref, the rest of the source up to the closing quote, and the trailing spaces and comment.
+var terraformSourceRegexp = regexp.MustCompile(
+	`^(\s*source\s*=\s*"(?:git::)?(?:https://|ssh://git@|git@)?github\.com[/:]([^/"?]+)/([^/"?]+?)(?:\.git)?(?://([^"?]*))?\?(?:[^"]*&)?ref=)` +
+		`([^"&#\s]+)([^"]*")(\s*(?:(?:#|//).*)?)$`,
+)
+
+// IsTerraformFile returns true if a path is a Terraform file (*.tf)
+func IsTerraformFile(path string) bool {
+	return filepath.Ext(path) == ".tf"
+}
+
+// parseTerraformSourceLine parses a "source" line of a Terraform module.
+// It returns false if the line is not a module source of a GitHub repository with a ref.
+func parseTerraformSourceLine(line string) (*refLine, bool) {
+	matches := terraformSourceRegexp.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, false
+	}
+
+	action := matches[2] + "/" + matches[3]
+	if subPath := strings.Trim(matches[4], "/"); subPath != "" {
+		action += "/" + subPath
+	}
+
+	return &refLine{
+		prefix:   matches[1],
+		action:   action,
+		ref:      matches[5],
+		suffix:   matches[6],
+		trailing: matches[7],
+		revOnly:  true,
+	}, true
+}
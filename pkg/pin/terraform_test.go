@@ -0,0 +1,100 @@
+package pin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTerraform = `module "vpc" {
+  source = "git::https://github.com/owner/modules.git//vpc?ref=v1.2.3" // vpc
+}
+
+module "dns" {
+  source  = "github.com/owner/dns?depth=1&ref=v2.0.0"
+  version = "2.0.0"
+}
+
+module "local" {
+  source = "./modules/local"
+}
+
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+`
+
+func TestIsTerraformFile(t *testing.T) {
+	a := assert.New(t)
+
+	a.True(IsTerraformFile("main.tf"))
+	a.True(IsTerraformFile("modules/vpc/main.tf"))
+	a.False(IsTerraformFile("terraform.tfvars"))
+	a.False(IsTerraformFile("main.tf.json"))
+}
+
+func TestScan_Terraform(t *testing.T) {
+	a := assert.New(t)
+
+	refs := Scan("main.tf", []byte(testTerraform))
+	a.Len(refs, 2)
+
+	a.Equal(Reference{
+		Path:    "main.tf",
+		Line:    2,
+		Action:  "owner/modules/vpc",
+		Owner:   "owner",
+		Repo:    "modules",
+		SubPath: "vpc",
+		Ref:     "v1.2.3",
+		Comment: "vpc",
+	}, refs[0])
+	a.True(refs[0].IsTerraformModule())
+	a.False(refs[0].IsAction())
+
+	a.Equal("owner/dns", refs[1].Action)
+	a.Equal("v2.0.0", refs[1].Ref)
+}
+
+func TestPinner_PinContent_Terraform(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	modulesHash := strings.Repeat("a", 40)
+	dnsHash := strings.Repeat("b", 40)
+	fake := &fakeResolver{hashes: map[string]string{
+		"owner/modules@v1.2.3": modulesHash,
+		"owner/dns@v2.0.0":     dnsHash,
+	}}
+
+	pinner, err := New(&Params{Resolver: fake})
+	r.NoError(err)
+
+	result := pinner.PinContent(context.Background(), "main.tf", []byte(testTerraform))
+	r.Len(result.Changes, 2)
+	a.Empty(result.Errors)
+
+	want := strings.NewReplacer(
+		`//vpc?ref=v1.2.3" // vpc`, `//vpc?ref=`+modulesHash+`" # v1.2.3 # vpc`,
+		`?depth=1&ref=v2.0.0"`, `?depth=1&ref=`+dnsHash+`" # v2.0.0`,
+	).Replace(testTerraform)
+	a.Equal(want, string(result.Pinned))
+
+	// the pinned sources are verified with the version comments
+	verifier, err := NewVerifier(&VerifierParams{
+		Resolver: fakeTagHashResolver{tagToHash: map[string]string{"v1.2.3": modulesHash, "v2.0.0": modulesHash}},
+	})
+	r.NoError(err)
+
+	results := verifier.VerifyContent(context.Background(), "main.tf", result.Pinned)
+	r.Len(results, 2)
+	a.Equal(VerifyStatusOK, results[0].Status)
+	a.Equal(VerifyStatusMismatch, results[1].Status)
+}
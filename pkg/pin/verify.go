@@ -0,0 +1,214 @@
+package pin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+// TagHashResolver resolves a tag to the hashes and a commit hash to the tags,
+// and verifies that a commit hash belongs to a repository
+type TagHashResolver interface {
+	ResolveFromTagContext(ctx context.Context, repo repository.Repository, tag string) (*resolver.GitTag, error)
+	ResolveFromHashContext(ctx context.Context, repo repository.Repository, hash string) ([]resolver.GitTag, error)
+	VerifyOriginContext(ctx context.Context, repo repository.Repository, hash string) (*resolver.Origin, error)
+}
+
+// VerifyStatus is the status of a verified reference
+type VerifyStatus string
+
+const (
+	// VerifyStatusOK is the status of a reference pinned to the commit hash that the version comment points to
+	VerifyStatusOK VerifyStatus = "ok"
+
+	// VerifyStatusMismatch is the status of a reference pinned to a commit hash
+	// that the version comment does not point to
+	VerifyStatusMismatch VerifyStatus = "mismatch"
+
+	// VerifyStatusUnpinned is the status of a reference that is not pinned to a commit hash
+	VerifyStatusUnpinned VerifyStatus = "unpinned"
+
+	// VerifyStatusNoComment is the status of a reference pinned to a commit hash without a version comment
+	VerifyStatusNoComment VerifyStatus = "noComment"
+
+	// VerifyStatusImpostor is the status of a reference pinned to a commit hash that is not reachable
+	// from any branch or tag of the repository, such as a commit of a fork
+	VerifyStatusImpostor VerifyStatus = "impostor"
+
+	// VerifyStatusError is the status of a reference that failed to be verified
+	VerifyStatusError VerifyStatus = "error"
+)
+
+// Verification represents the result of verifying a reference
+type Verification struct {
+	Reference
+
+	// Status is the status of the reference
+	Status VerifyStatus
+
+	// Version is the tag of the version comment (e.g. "v4.1.6" of "# v4.1.6")
+	Version string
+
+	// VersionCommitHash is the commit hash that the version tag points to.
+	// Empty if the version tag is not resolved.
+	VersionCommitHash string
+
+	// PinnedTags is the tags that the pinned commit hash is resolved to
+	PinnedTags []string
+
+	// Message is the explanation of the status
+	Message string
+}
+
+// OK returns true if the reference is pinned to the commit hash that the version comment points to
+func (v Verification) OK() bool {
+	return v.Status == VerifyStatusOK
+}
+
+// VerifierParams represents the parameters for NewVerifier
+type VerifierParams struct {
+	// Resolver is a resolver of the tags and the hashes of the action repositories
+	Resolver TagHashResolver
+
+	// Logger is a Logger used by the verifier
+	Logger *slog.Logger
+}
+
+// Verifier verifies that the references pinned to commit hashes match the version comments
+type Verifier struct {
+	resolver TagHashResolver
+	logger   *slog.Logger
+}
+
+// NewVerifier creates a new verifier
+func NewVerifier(params *VerifierParams) (*Verifier, error) {
+	if params.Resolver == nil {
+		return nil, errors.New("required a resolver")
+	}
+
+	logger := params.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Verifier{
+		resolver: params.Resolver,
+		logger:   logger,
+	}, nil
+}
+
+// versionOf returns the version tag of a version comment: the first word of the comment.
+// The "frozen:" prefix of a pre-commit config file is ignored.
+func versionOf(comment string) string {
+	fields := strings.Fields(strings.TrimPrefix(comment, frozenPrefix))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return fields[0]
+}
+
+// Verify verifies a reference. The pinned commit hash is verified to be reachable from a branch or a tag
+// of the repository at first. Then the pinned commit hash is resolved to the tags,
+// and the version tag is resolved to the hashes if the tags do not include the version.
+func (v *Verifier) Verify(ctx context.Context, ref Reference) Verification {
+	result := Verification{
+		Reference:  ref,
+		Version:    versionOf(ref.Comment),
+		PinnedTags: []string{},
+	}
+
+	if !ref.IsPinned() {
+		result.Status = VerifyStatusUnpinned
+		result.Message = "not pinned to a commit hash"
+		return result
+	}
+
+	repo := ref.Repository()
+	v.logger.Debug("verifying a reference", slog.String("ref", ref.String()), slog.String("version", result.Version))
+
+	origin, err := v.resolver.VerifyOriginContext(ctx, repo, ref.Ref)
+	if err != nil {
+		result.Status = VerifyStatusError
+		result.Message = fmt.Sprintf("failed to verify the origin: %s", err)
+		return result
+	}
+	if !origin.Reachable {
+		result.Status = VerifyStatusImpostor
+		result.Message = fmt.Sprintf("the commit is not reachable from any branch or tag of %s", origin.RepoID)
+		return result
+	}
+
+	if result.Version == "" {
+		result.Status = VerifyStatusNoComment
+		result.Message = "no version comment"
+		return result
+	}
+
+	gitTags, err := v.resolver.ResolveFromHashContext(ctx, repo, ref.Ref)
+	if err == nil {
+		for _, gitTag := range gitTags {
+			result.PinnedTags = append(result.PinnedTags, gitTag.Tag)
+
+			if gitTag.Tag == result.Version {
+				result.Status = VerifyStatusOK
+				result.VersionCommitHash = gitTag.CommitHash
+			}
+		}
+
+		if result.OK() {
+			return result
+		}
+	} else {
+		v.logger.Debug("failed to resolve a pinned hash", slog.String("ref", ref.String()), slog.String("error", err.Error()))
+	}
+
+	gitTag, err := v.resolver.ResolveFromTagContext(ctx, repo, result.Version)
+	if err != nil {
+		result.Status = VerifyStatusError
+		result.Message = fmt.Sprintf("failed to resolve %s: %s", result.Version, err)
+		return result
+	}
+
+	result.VersionCommitHash = gitTag.CommitHash
+
+	if gitTag.CommitHash == ref.Ref {
+		result.Status = VerifyStatusOK
+		return result
+	}
+
+	result.Status = VerifyStatusMismatch
+	result.Message = fmt.Sprintf("%s points to %s", result.Version, gitTag.CommitHash)
+	if len(result.PinnedTags) > 0 {
+		result.Message += fmt.Sprintf(", the pinned hash is %s", strings.Join(result.PinnedTags, ", "))
+	}
+
+	return result
+}
+
+// VerifyContent verifies the references to remote actions in a content
+func (v *Verifier) VerifyContent(ctx context.Context, path string, content []byte) []Verification {
+	results := []Verification{}
+
+	for _, ref := range Scan(path, content) {
+		results = append(results, v.Verify(ctx, ref))
+	}
+
+	return results
+}
+
+// VerifyFile verifies the references to remote actions in a file
+func (v *Verifier) VerifyFile(ctx context.Context, path string) ([]Verification, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return v.VerifyContent(ctx, path, content), nil
+}
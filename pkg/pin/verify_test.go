@@ -0,0 +1,105 @@
+package pin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+// fakeTagHashResolver resolves the tags and the hashes from a map of tags to commit hashes
+type fakeTagHashResolver struct {
+	tagToHash map[string]string
+
+	// impostors is the commit hashes that are not reachable from any branch or tag
+	impostors map[string]bool
+}
+
+func (f fakeTagHashResolver) VerifyOriginContext(ctx context.Context, repo repository.Repository, hash string) (*resolver.Origin, error) {
+	origin := &resolver.Origin{RepoID: resolver.ToRepoID(repo), CommitHash: hash}
+	if !f.impostors[hash] {
+		origin.Reachable = true
+		origin.Source = resolver.OriginSourceGit
+	}
+
+	return origin, nil
+}
+
+func (f fakeTagHashResolver) ResolveFromTagContext(ctx context.Context, repo repository.Repository, tag string) (*resolver.GitTag, error) {
+	hash, ok := f.tagToHash[tag]
+	if !ok {
+		return nil, fmt.Errorf("tag not found: %s", tag)
+	}
+
+	return &resolver.GitTag{Tag: tag, BaseTag: tag, CommitHash: hash, TagHash: hash}, nil
+}
+
+func (f fakeTagHashResolver) ResolveFromHashContext(ctx context.Context, repo repository.Repository, hash string) ([]resolver.GitTag, error) {
+	gitTags := []resolver.GitTag{}
+	for tag, h := range f.tagToHash {
+		if h == hash {
+			gitTags = append(gitTags, resolver.GitTag{Tag: tag, BaseTag: tag, CommitHash: h, TagHash: h})
+		}
+	}
+
+	if len(gitTags) == 0 {
+		return nil, fmt.Errorf("hash not found: %s", hash)
+	}
+
+	return gitTags, nil
+}
+
+func TestVerifier_VerifyContent(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	v415 := strings.Repeat("a", 40)
+	v416 := strings.Repeat("b", 40)
+	unknown := strings.Repeat("c", 40)
+	impostor := strings.Repeat("d", 40)
+
+	verifier, err := NewVerifier(&VerifierParams{
+		Resolver: fakeTagHashResolver{
+			tagToHash: map[string]string{"v4.1.5": v415, "v4.1.6": v416},
+			impostors: map[string]bool{impostor: true},
+		},
+	})
+	r.NoError(err)
+
+	content := strings.Join([]string{
+		"steps:",
+		"  - uses: actions/checkout@" + v416 + " # v4.1.6",
+		"  - uses: actions/checkout@" + v415 + " # v4.1.6",
+		"  - uses: actions/checkout@v4",
+		"  - uses: actions/checkout@" + v416,
+		"  - uses: actions/checkout@" + unknown + " # v9.9.9",
+		"  - uses: actions/checkout@" + unknown + " # v4.1.6 # checkout",
+		"  - uses: actions/checkout@" + impostor + " # v4.1.6",
+		"",
+	}, "\n")
+
+	results := verifier.VerifyContent(context.Background(), "ci.yml", []byte(content))
+	r.Len(results, 7)
+
+	a.Equal(VerifyStatusOK, results[0].Status)
+	a.True(results[0].OK())
+
+	a.Equal(VerifyStatusMismatch, results[1].Status)
+	a.Equal(v416, results[1].VersionCommitHash)
+	a.Equal([]string{"v4.1.5"}, results[1].PinnedTags)
+
+	a.Equal(VerifyStatusUnpinned, results[2].Status)
+	a.Equal(VerifyStatusNoComment, results[3].Status)
+	a.Equal(VerifyStatusError, results[4].Status)
+
+	a.Equal(VerifyStatusMismatch, results[5].Status)
+	a.Equal("v4.1.6", results[5].Version)
+	a.Empty(results[5].PinnedTags)
+
+	a.Equal(VerifyStatusImpostor, results[6].Status)
+}
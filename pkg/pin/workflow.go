@@ -0,0 +1,307 @@
+package pin
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+// usesRegexp matches a "uses:" line of a workflow file or an action metadata file.
+// The groups are: the prefix up to the value, the opening quote, the action, the ref, the closing quote,
+// and the trailing spaces and comment.
+var usesRegexp = regexp.MustCompile(`^(\s*(?:-\s+)?uses:\s*)(["']?)([^"'\s@#]+)@([^"'\s#]+)(["']?)(\s*(?:#.*)?)$`)
+
+var skipDirs = map[string]bool{
+	".git":         true,
+	".terraform":   true,
+	"node_modules": true,
+}
+
+// Reference represents an action reference of a "uses:" line (owner/repo[/path]@ref)
+type Reference struct {
+	// Path is the path to the file that has the reference
+	Path string
+
+	// Line is the 1-based line number of the reference
+	Line int
+
+	// Action is the action name formatted as "owner/repo[/path]"
+	Action string
+
+	// Owner is the owner of the action repository
+	Owner string
+
+	// Repo is the name of the action repository
+	Repo string
+
+	// SubPath is the path in the action repository (e.g. the path to a reusable workflow).
+	// Empty for an action at the root of the repository.
+	SubPath string
+
+	// Ref is the tag, the branch or the commit hash after "@"
+	Ref string
+
+	// Comment is the trailing comment without the leading "#".
+	// Empty if the line does not have a comment.
+	Comment string
+}
+
+func (r Reference) String() string {
+	return fmt.Sprintf("%s:%d: %s@%s", r.Path, r.Line, r.Action, r.Ref)
+}
+
+// Repository returns the action repository
+func (r Reference) Repository() repository.Repository {
+	return repository.Repository{Host: "github.com", Owner: r.Owner, Name: r.Repo}
+}
+
+// IsPinned returns true if the reference is a full commit hash
+func (r Reference) IsPinned() bool {
+	return resolver.IsSHA(r.Ref)
+}
+
+// IsPreCommitHook returns true if the reference is a hook repository of a pre-commit config file
+func (r Reference) IsPreCommitHook() bool {
+	return IsPreCommitConfigFile(r.Path)
+}
+
+// IsTerraformModule returns true if the reference is a module source of a Terraform file
+func (r Reference) IsTerraformModule() bool {
+	return IsTerraformFile(r.Path)
+}
+
+// IsAction returns true if the reference is an action or a reusable workflow
+func (r Reference) IsAction() bool {
+	return !r.IsPreCommitHook() && !r.IsTerraformModule()
+}
+
+// refLine represents a line of a reference split into the parts to rewrite
+type refLine struct {
+	prefix   string
+	quote    string
+	action   string
+	ref      string
+	suffix   string
+	trailing string
+
+	// revOnly is true if the ref is written without the action
+	// (e.g. a "rev:" line of a pre-commit config file or "?ref=" of a Terraform module source)
+	revOnly bool
+}
+
+func (l refLine) format(ref, trailing string) string {
+	if l.revOnly {
+		return l.prefix + l.quote + ref + l.quote + l.suffix + trailing
+	}
+
+	return l.prefix + l.quote + l.action + "@" + ref + l.quote + trailing
+}
+
+// lineParser parses a line of a file. It returns false if the line is not a reference to a remote repository.
+type lineParser func(line string) (*refLine, bool)
+
+// newLineParser returns a parser for the lines of a file.
+// The parser of a pre-commit config file keeps the state of the preceding lines.
+func newLineParser(path string) lineParser {
+	if IsPreCommitConfigFile(path) {
+		return newPreCommitParser()
+	}
+
+	if IsTerraformFile(path) {
+		return parseTerraformSourceLine
+	}
+
+	return parseUsesLine
+}
+
+// parseUsesLine parses a "uses:" line. It returns false if the line is not a reference to a remote action.
+// Local actions (./path) and Docker images (docker://) are not references to a remote action.
+func parseUsesLine(line string) (*refLine, bool) {
+	matches := usesRegexp.FindStringSubmatch(line)
+	if matches == nil || matches[2] != matches[5] {
+		return nil, false
+	}
+
+	action := matches[3]
+	if strings.HasPrefix(action, ".") || strings.Contains(action, "://") {
+		return nil, false
+	}
+
+	if strings.Count(action, "/") < 1 {
+		return nil, false
+	}
+
+	return &refLine{
+		prefix:   matches[1],
+		quote:    matches[2],
+		action:   action,
+		ref:      matches[4],
+		trailing: matches[6],
+	}, true
+}
+
+func newReference(path string, lineNum int, u refLine) Reference {
+	parts := strings.SplitN(u.action, "/", 3)
+
+	ref := Reference{
+		Path:    path,
+		Line:    lineNum,
+		Action:  u.action,
+		Owner:   parts[0],
+		Repo:    parts[1],
+		Ref:     u.ref,
+		Comment: trimComment(u.trailing),
+	}
+	if len(parts) > 2 {
+		ref.SubPath = parts[2]
+	}
+
+	return ref
+}
+
+// trimComment returns the body of a trailing comment without the leading "#" (or "//" of HCL)
+func trimComment(trailing string) string {
+	trailing = strings.TrimSpace(trailing)
+	if strings.HasPrefix(trailing, "//") {
+		return strings.TrimSpace(strings.TrimPrefix(trailing, "//"))
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(trailing, "#"))
+}
+
+// splitLines splits a content into lines. The line endings are kept in the lines.
+func splitLines(content string) []string {
+	return strings.SplitAfter(content, "\n")
+}
+
+// trimLineEnding splits a line into the body and the line ending ("\n", "\r\n" or empty)
+func trimLineEnding(line string) (string, string) {
+	body := strings.TrimRight(line, "\r\n")
+	return body, line[len(body):]
+}
+
+// Scan finds the references to remote repositories in the content of a workflow file, an action metadata file
+// or a pre-commit config file
+func Scan(path string, content []byte) []Reference {
+	refs := []Reference{}
+	parse := newLineParser(path)
+
+	for i, line := range splitLines(string(content)) {
+		body, _ := trimLineEnding(line)
+
+		u, ok := parse(body)
+		if !ok {
+			continue
+		}
+
+		refs = append(refs, newReference(path, i+1, *u))
+	}
+
+	return refs
+}
+
+// rewriteReferences rewrites the lines of the references to remote repositories in the content of a file.
+// The rewrite function receives the 1-based line number and the parsed line, and returns the new line without
+// the line ending. The line is kept as it is if the function returns false.
+func rewriteReferences(path string, content []byte, rewrite func(lineNum int, u refLine) (string, bool)) []byte {
+	var sb strings.Builder
+	parse := newLineParser(path)
+
+	for i, line := range splitLines(string(content)) {
+		body, lineEnding := trimLineEnding(line)
+
+		u, ok := parse(body)
+		if !ok {
+			sb.WriteString(line)
+			continue
+		}
+
+		newBody, ok := rewrite(i+1, *u)
+		if !ok {
+			sb.WriteString(line)
+			continue
+		}
+
+		sb.WriteString(newBody)
+		sb.WriteString(lineEnding)
+	}
+
+	return []byte(sb.String())
+}
+
+// IsWorkflowFile returns true if a path is a workflow file (.github/workflows/*.yml)
+// or an action metadata file (action.yml)
+func IsWorkflowFile(path string) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+
+	if base == "action.yml" || base == "action.yaml" {
+		return true
+	}
+
+	dir := filepath.ToSlash(filepath.Dir(path))
+
+	return dir == ".github/workflows" || strings.HasSuffix(dir, "/.github/workflows")
+}
+
+// FindWorkflowFiles finds the workflow files, the action metadata files, the pre-commit config files
+// and the Terraform files under the paths.
+// A path to a file is returned as it is.
+func FindWorkflowFiles(paths []string) ([]string, error) {
+	return findFiles(paths, func(path string) bool {
+		return IsWorkflowFile(path) || IsPreCommitConfigFile(path) || IsTerraformFile(path)
+	})
+}
+
+// findFiles finds the files that match a function under the paths. A path to a file is returned as it is.
+func findFiles(paths []string, match func(path string) bool) ([]string, error) {
+	files := []string{}
+
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the file info: %w", err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				if path != root && skipDirs[d.Name()] {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if match(path) {
+				files = append(files, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find files in %s: %w", root, err)
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,99 @@
+package resolver
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// AliasGroup represents the tags that point to the same commit,
+// such as a moving major tag and the release tags (e.g. v4, v4.1 and v4.1.6)
+type AliasGroup struct {
+	// RepoID is the GitHub repository ID formatted as "owner/name"
+	RepoID string
+
+	// CommitHash is the git commit hash that the tags point to
+	CommitHash string
+
+	// Tags is the tags that point to the commit in the order of the tag names
+	Tags []GitTag
+
+	// MostSpecificTag is the semantic version tag that has the most version components in the group.
+	// Empty if the group has no semantic version tag.
+	MostSpecificTag string
+}
+
+// TagNames returns the names of the tags in the group
+func (g AliasGroup) TagNames() []string {
+	names := make([]string, 0, len(g.Tags))
+	for _, gitTag := range g.Tags {
+		names = append(names, gitTag.Tag)
+	}
+
+	return names
+}
+
+// ResolveAliases resolves a tag or a hash to the group of the tags that point to the same commit.
+// A hash is looked up only in the tag list of the repository, and the group is empty if no tag points to the hash.
+// The records resolved by 'git describe' are not the aliases.
+func (r Resolver) ResolveAliases(ctx context.Context, repo repository.Repository, tagOrHash string) (*AliasGroup, error) {
+	repoID := ToRepoID(repo)
+
+	// the group is built from the tag list rather than the cached records of the tags,
+	// since the records of the alias tags expire earlier than the others
+	if err := r.touchRepo(ctx, repoID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	gitTags, err := r.listTags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var commitHash string
+
+	if IsSHA(tagOrHash) {
+		// look up the hash only in the tag list rather than describing it with a clone of the repository
+		commitHash = tagOrHash
+		for _, gitTag := range gitTags {
+			if gitTag.TagHash == tagOrHash {
+				commitHash = gitTag.CommitHash
+				break
+			}
+		}
+	} else {
+		for _, gitTag := range gitTags {
+			if gitTag.Tag == tagOrHash {
+				commitHash = gitTag.CommitHash
+				break
+			}
+		}
+
+		if commitHash == "" {
+			gitTag, err := r.ResolveFromTagContext(ctx, repo, tagOrHash)
+			if err != nil {
+				return nil, err
+			}
+
+			commitHash = gitTag.CommitHash
+		}
+	}
+
+	r.logger.Debug("resolving aliases", slog.String("repo", repoID), slog.String("commitHash", commitHash))
+
+	group := &AliasGroup{
+		RepoID:     repoID,
+		CommitHash: commitHash,
+		Tags:       []GitTag{},
+	}
+	for _, gitTag := range gitTags {
+		if gitTag.CommitHash == commitHash {
+			group.Tags = append(group.Tags, gitTag)
+		}
+	}
+	group.MostSpecificTag = mostSpecificVersionTag(group.TagNames())
+
+	return group, nil
+}
@@ -0,0 +1,138 @@
+package resolver
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_ResolveAliases(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	resolver := newTestResolver(t, nil)
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+	commitHash := strings.Repeat("a", 40)
+	annotatedTagHash := strings.Repeat("b", 40)
+	otherHash := Hash{CommitHash: strings.Repeat("c", 40), TagHash: strings.Repeat("c", 40)}
+
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{
+		"v4":     {CommitHash: commitHash, TagHash: commitHash},
+		"v4.1":   {CommitHash: commitHash, TagHash: commitHash},
+		"v4.1.6": {CommitHash: commitHash, TagHash: annotatedTagHash},
+		"v4.1.5": otherHash,
+	}, time.Now()))
+
+	for _, value := range []string{"v4", "v4.1.6", commitHash, annotatedTagHash} {
+		group, err := resolver.ResolveAliases(ctx, repo, value)
+		r.NoError(err, value)
+		a.Equal(commitHash, group.CommitHash, value)
+		a.Equal([]string{"v4", "v4.1", "v4.1.6"}, group.TagNames(), value)
+		a.Equal("v4.1.6", group.MostSpecificTag, value)
+	}
+
+	group, err := resolver.ResolveAliases(ctx, repo, "v4.1.5")
+	r.NoError(err)
+	a.Equal([]string{"v4.1.5"}, group.TagNames())
+	a.Equal("v4.1.5", group.MostSpecificTag)
+}
+
+func TestResolver_ResolveAliases_ExpiredAliases(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	commitHash := strings.Repeat("a", 40)
+	taghashMap := map[string]Hash{
+		"v4":     {CommitHash: commitHash, TagHash: commitHash},
+		"v4.1":   {CommitHash: commitHash, TagHash: commitHash},
+		"v4.1.6": {CommitHash: commitHash, TagHash: strings.Repeat("b", 40)},
+	}
+
+	fake := newFakeGraphQL()
+	fake.setTags("owner/a", taghashMap)
+
+	resolver := newTestResolver(t, &Params{Client: fake.client(t), CacheTTL: *NewCacheTTL(time.Hour)})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	// the records of the alias tags (v4 and v4.1) are expired while v4.1.6 is still cached
+	r.NoError(resolver.storeTags(ctx, repo, taghashMap, time.Now().Add(-30*time.Minute)))
+
+	group, err := resolver.ResolveAliases(ctx, repo, "v4.1.6")
+	r.NoError(err)
+	a.Equal(commitHash, group.CommitHash)
+	a.Equal([]string{"v4", "v4.1", "v4.1.6"}, group.TagNames())
+	a.Equal(1, fake.queries("owner/a"))
+}
+
+func TestResolver_ResolveAliases_UntaggedHash(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	commitHash := strings.Repeat("a", 40)
+	untaggedHash := strings.Repeat("d", 40)
+
+	fake := newFakeGraphQL()
+	fake.setTags("owner/a", map[string]Hash{
+		"v4.1.6": {CommitHash: commitHash, TagHash: commitHash},
+	})
+
+	// the hash is not described with a clone of the repository
+	resolver := newTestResolver(t, &Params{
+		Client:          fake.client(t),
+		GitDescExecutor: localGitExecutor{dirPath: filepath.Join(t.TempDir(), "not-exist")},
+	})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	group, err := resolver.ResolveAliases(ctx, repo, untaggedHash)
+	r.NoError(err)
+	a.Equal(untaggedHash, group.CommitHash)
+	a.Empty(group.Tags)
+	a.Empty(group.MostSpecificTag)
+	a.Equal(1, fake.queries("owner/a"))
+}
+
+func TestResolver_ResolveAliases_DescribeRecord(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	resolver := newTestResolver(t, nil)
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+	commitHash := strings.Repeat("a", 40)
+	untaggedHash := strings.Repeat("d", 40)
+
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{
+		"v4.1.6": {CommitHash: commitHash, TagHash: commitHash},
+	}, now))
+
+	// the records resolved by 'git describe'
+	for _, gitTag := range []GitTag{
+		{Tag: "v4.1.6-0-gaaaaaaaaaa", BaseTag: "v4.1.6", CommitHash: commitHash, TagHash: commitHash, DescribeKey: "--long"},
+		{Tag: "v4.1.6-4-gdddddddddd", BaseTag: "v4.1.6", CommitHash: untaggedHash, TagHash: untaggedHash},
+	} {
+		gitTag.RepoID = ToRepoID(repo)
+		gitTag.Source = SourceGit
+		gitTag.FetchedAt = now
+		gitTag.ExpiredAt = now.Add(time.Hour)
+		r.NoError(resolver.db.Create(&gitTag).Error)
+	}
+
+	group, err := resolver.ResolveAliases(ctx, repo, commitHash)
+	r.NoError(err)
+	a.Equal([]string{"v4.1.6"}, group.TagNames())
+
+	group, err = resolver.ResolveAliases(ctx, repo, untaggedHash)
+	r.NoError(err)
+	a.Empty(group.Tags)
+	a.Empty(group.MostSpecificTag)
+}
@@ -0,0 +1,353 @@
+package resolver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	graphql "github.com/cli/shurcooL-graphql"
+	"gorm.io/gorm"
+)
+
+// Basis is the basis of a point-in-time resolution
+type Basis string
+
+const (
+	// BasisObserved means that the tag is observed pointing to the object at the time
+	BasisObserved Basis = "observed"
+
+	// BasisInferred means that the result is inferred from the observations before or after the time,
+	// and the tagger date and the commit date of the object
+	BasisInferred Basis = "inferred"
+)
+
+// AsOfResult represents a result of a point-in-time resolution
+type AsOfResult struct {
+	// GitTag is the tag and the hashes that the tag pointed to at the time
+	GitTag GitTag
+
+	// AsOf is the time of the resolution
+	AsOf time.Time
+
+	// Basis is the basis of the result
+	Basis Basis
+
+	// Reason is the explanation of the basis
+	Reason string
+
+	// TaggedAt is the tagger date of the annotated tag object.
+	// nil for lightweight tags or if the date is not used for the resolution.
+	TaggedAt *time.Time
+
+	// CommittedAt is the commit date of the commit.
+	// nil if the date is not used for the resolution.
+	CommittedAt *time.Time
+}
+
+// objectDates represents the dates of a git object
+type objectDates struct {
+	TaggedAt    *time.Time
+	CommittedAt *time.Time
+}
+
+// fetchObjectDates fetches the tagger date and the commit date of a git object from GitHub
+func (r Resolver) fetchObjectDates(repo repository.Repository, oid string) (*objectDates, error) {
+	type commitFragment struct {
+		CommittedDate time.Time
+	}
+
+	var query struct {
+		Repository struct {
+			Object *struct {
+				Tag struct {
+					Tagger *struct {
+						Date time.Time
+					}
+					Target struct {
+						Commit commitFragment `graphql:"... on Commit"`
+					}
+				} `graphql:"... on Tag"`
+				Commit commitFragment `graphql:"... on Commit"`
+			} `graphql:"object(expression: $expression)"`
+		} `graphql:"repository(owner:$owner, name:$name)"`
+	}
+
+	variables := map[string]interface{}{
+		"owner":      graphql.String(repo.Owner),
+		"name":       graphql.String(repo.Name),
+		"expression": graphql.String(oid),
+	}
+
+	r.logger.Debug("fetching object dates", slog.String("repo", ToRepoID(repo)), slog.String("oid", oid))
+
+	if err := r.gqlClient.Query("object_dates", &query, variables); err != nil {
+		return nil, fmt.Errorf("error fetching object dates: %w", err)
+	}
+
+	object := query.Repository.Object
+	if object == nil {
+		return nil, fmt.Errorf("object not found: %s", oid)
+	}
+
+	dates := &objectDates{}
+
+	if object.Tag.Tagger != nil {
+		taggedAt := object.Tag.Tagger.Date
+		dates.TaggedAt = &taggedAt
+	}
+
+	for _, committedAt := range []time.Time{object.Tag.Target.Commit.CommittedDate, object.Commit.CommittedDate} {
+		if !committedAt.IsZero() {
+			dates.CommittedAt = &committedAt
+			break
+		}
+	}
+
+	return dates, nil
+}
+
+func historyToGitTag(history GitTagHistory) GitTag {
+	return GitTag{
+		RepoID:     history.RepoID,
+		Tag:        history.Tag,
+		BaseTag:    history.Tag,
+		TagHash:    history.TagHash,
+		CommitHash: history.CommitHash,
+	}
+}
+
+// tagHistoriesForAsOf returns the history of a tag in chronological order.
+// If the repository has never been observed, the tags are fetched at first.
+func (r *Resolver) tagHistoriesForAsOf(ctx context.Context, repo repository.Repository, tag string) ([]GitTagHistory, error) {
+	histories, err := r.TagHistory(ctx, repo, tag)
+	if err != nil {
+		return nil, err
+	}
+	if len(histories) > 0 {
+		return histories, nil
+	}
+
+	now := time.Now()
+	if err := r.updateCacheDB(ctx, r.gqlClient, repo, &now); err != nil {
+		return nil, fmt.Errorf("failed to update the cache database: %w", err)
+	}
+
+	return r.TagHistory(ctx, repo, tag)
+}
+
+// resolveTagAsOf resolves a tag to the hashes that the tag pointed to at the time
+func (r *Resolver) resolveTagAsOf(ctx context.Context, repo repository.Repository, tag string, asOf time.Time) (*AsOfResult, error) {
+	histories, err := r.tagHistoriesForAsOf(ctx, repo, tag)
+	if err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 {
+		return nil, fmt.Errorf("the tag has never been observed: %s", tag)
+	}
+
+	sort.SliceStable(histories, func(i, j int) bool {
+		return histories[i].FirstSeenAt.Before(histories[j].FirstSeenAt)
+	})
+
+	// the time is before the first observation
+	if asOf.Before(histories[0].FirstSeenAt) {
+		return r.inferBeforeObservation(repo, histories[0], asOf)
+	}
+
+	for i, history := range histories {
+		result := &AsOfResult{
+			GitTag: historyToGitTag(history),
+			AsOf:   asOf,
+		}
+
+		if !asOf.After(history.LastSeenAt) {
+			result.Basis = BasisObserved
+			result.Reason = fmt.Sprintf("observed from %s to %s",
+				history.FirstSeenAt.Format(time.RFC3339), history.LastSeenAt.Format(time.RFC3339))
+
+			return result, nil
+		}
+
+		if history.UpstreamDeletedAt != nil && !asOf.Before(*history.UpstreamDeletedAt) {
+			if i+1 < len(histories) && !asOf.Before(histories[i+1].FirstSeenAt) {
+				continue
+			}
+
+			return nil, fmt.Errorf("the tag was deleted upstream at %s: %s",
+				history.UpstreamDeletedAt.Format(time.RFC3339), tag)
+		}
+
+		if i+1 < len(histories) && !asOf.Before(histories[i+1].FirstSeenAt) {
+			continue
+		}
+
+		// the time is in the gap between the last observation and the next observation
+		result.Basis = BasisInferred
+		result.Reason = fmt.Sprintf("last observed at %s", history.LastSeenAt.Format(time.RFC3339))
+
+		if i+1 < len(histories) {
+			next := histories[i+1]
+
+			// a tagger date is the time when the annotated tag object is created
+			dates, err := r.fetchObjectDates(repo, next.TagHash)
+			if err != nil {
+				// the history is enough to infer the hashes without the tagger date
+				r.logger.Warn("failed to fetch the dates of a tag object",
+					slog.String("repo", ToRepoID(repo)),
+					slog.String("oid", next.TagHash),
+					slog.String("error", err.Error()))
+
+				dates = &objectDates{}
+			}
+			if dates.TaggedAt != nil && !asOf.Before(*dates.TaggedAt) {
+				result.GitTag = historyToGitTag(next)
+				result.TaggedAt = dates.TaggedAt
+				result.Reason = fmt.Sprintf("the tag object observed at %s was created at %s",
+					next.FirstSeenAt.Format(time.RFC3339), dates.TaggedAt.Format(time.RFC3339))
+			} else {
+				result.Reason += fmt.Sprintf(", and moved by %s", next.FirstSeenAt.Format(time.RFC3339))
+			}
+		}
+
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("failed to resolve the tag as of %s: %s", asOf.Format(time.RFC3339), tag)
+}
+
+// inferBeforeObservation infers the hashes that a tag pointed to before the first observation
+// from the tagger date and the commit date of the first observed object
+func (r Resolver) inferBeforeObservation(repo repository.Repository, first GitTagHistory, asOf time.Time) (*AsOfResult, error) {
+	dates, err := r.fetchObjectDates(repo, first.TagHash)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AsOfResult{
+		GitTag:      historyToGitTag(first),
+		AsOf:        asOf,
+		Basis:       BasisInferred,
+		TaggedAt:    dates.TaggedAt,
+		CommittedAt: dates.CommittedAt,
+	}
+
+	switch {
+	case dates.TaggedAt != nil:
+		if asOf.Before(*dates.TaggedAt) {
+			return nil, fmt.Errorf("the tag object was created at %s, the tag did not exist or pointed to another object: %s",
+				dates.TaggedAt.Format(time.RFC3339), first.Tag)
+		}
+
+		result.Reason = fmt.Sprintf("the tag object was created at %s and first observed at %s",
+			dates.TaggedAt.Format(time.RFC3339), first.FirstSeenAt.Format(time.RFC3339))
+
+	case dates.CommittedAt != nil:
+		if asOf.Before(*dates.CommittedAt) {
+			return nil, fmt.Errorf("the commit was created at %s, the tag did not exist or pointed to another object: %s",
+				dates.CommittedAt.Format(time.RFC3339), first.Tag)
+		}
+
+		result.Reason = fmt.Sprintf("the commit was created at %s and first observed at %s, the lightweight tag may be created later",
+			dates.CommittedAt.Format(time.RFC3339), first.FirstSeenAt.Format(time.RFC3339))
+
+	default:
+		return nil, fmt.Errorf("failed to get the dates of the object: %s", first.TagHash)
+	}
+
+	return result, nil
+}
+
+// resolveHashAsOf resolves a hash to the tags that pointed to the hash at the time
+func (r *Resolver) resolveHashAsOf(ctx context.Context, repo repository.Repository, hash string, asOf time.Time) ([]AsOfResult, error) {
+	repoID := ToRepoID(repo)
+	whereHash := r.db.Where(&GitTagHistory{RepoID: repoID, TagHash: hash}).Or(&GitTagHistory{RepoID: repoID, CommitHash: hash})
+
+	selectTags := func() ([]string, error) {
+		var tags []string
+
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return tx.Model(&GitTagHistory{}).Where(whereHash).Distinct().Order("tag").Pluck("tag", &tags).Error
+		}, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to select tag histories: %w", err)
+		}
+
+		return tags, nil
+	}
+
+	tags, err := selectTags()
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		now := time.Now()
+		if err := r.updateCacheDB(ctx, r.gqlClient, repo, &now); err != nil {
+			return nil, fmt.Errorf("failed to update the cache database: %w", err)
+		}
+
+		if tags, err = selectTags(); err != nil {
+			return nil, err
+		}
+	}
+
+	results := []AsOfResult{}
+
+	for _, tag := range tags {
+		result, err := r.resolveTagAsOf(ctx, repo, tag, asOf)
+		if err != nil {
+			r.logger.Debug("the tag is not resolved as of the time", slog.String("tag", tag), slog.Any("error", err))
+			continue
+		}
+
+		if result.GitTag.TagHash == hash || result.GitTag.CommitHash == hash {
+			results = append(results, *result)
+		}
+	}
+
+	return results, nil
+}
+
+// ResolveAsOf resolves a tag or a commit hash as of the specified time.
+// Results are based on the tag history observed by the resolver,
+// and the tagger dates and the commit dates of the objects for the time without observations.
+// AsOfResult.Basis tells whether a result is based on a direct observation or an inference.
+func (r *Resolver) ResolveAsOf(ctx context.Context, repo repository.Repository, tagOrHash string, asOf time.Time) ([]AsOfResult, error) {
+	if tagOrHash == "" {
+		return nil, errors.New("require a tag or a hash")
+	}
+
+	repoID := ToRepoID(repo)
+	r.logger.Debug("resolving as of the time",
+		slog.String("repo", repoID),
+		slog.String("from", tagOrHash),
+		slog.String("asOf", asOf.Format(time.RFC3339)),
+	)
+
+	if err := r.touchRepo(ctx, repoID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if IsSHA(tagOrHash) {
+		results, err := r.resolveHashAsOf(ctx, repo, tagOrHash, asOf)
+		if err != nil {
+			return nil, err
+		}
+		if len(results) == 0 {
+			return nil, fmt.Errorf("no tag pointed to the hash as of %s: %s", asOf.Format(time.RFC3339), tagOrHash)
+		}
+
+		return results, nil
+	}
+
+	result, err := r.resolveTagAsOf(ctx, repo, tagOrHash, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return []AsOfResult{*result}, nil
+}
@@ -0,0 +1,69 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_ResolveAsOf(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	// the fake fails to fetch the dates of the objects
+	resolver := newTestResolver(t, &Params{Client: newFakeGraphQL().client(t)})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+	hashA := Hash{CommitHash: strings.Repeat("a", 40), TagHash: strings.Repeat("a", 40)}
+	hashB := Hash{CommitHash: strings.Repeat("b", 40), TagHash: strings.Repeat("b", 40)}
+	base := time.Now().Add(-time.Hour)
+
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1": hashA, "v1.0.0": hashA}, base))
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1": hashA, "v1.0.0": hashA}, base.Add(10*time.Minute)))
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1": hashB, "v1.1.0": hashB}, base.Add(20*time.Minute)))
+
+	// observed before the move
+	results, err := resolver.ResolveAsOf(ctx, repo, "v1", base.Add(5*time.Minute))
+	r.NoError(err)
+	r.Len(results, 1)
+	a.Equal(BasisObserved, results[0].Basis)
+	a.Equal(hashA.CommitHash, results[0].GitTag.CommitHash)
+
+	// observed after the move
+	results, err = resolver.ResolveAsOf(ctx, repo, "v1", base.Add(20*time.Minute))
+	r.NoError(err)
+	r.Len(results, 1)
+	a.Equal(BasisObserved, results[0].Basis)
+	a.Equal(hashB.CommitHash, results[0].GitTag.CommitHash)
+
+	// in the gap between the observations: inferred from the history without the dates of the objects
+	results, err = resolver.ResolveAsOf(ctx, repo, "v1", base.Add(15*time.Minute))
+	r.NoError(err)
+	r.Len(results, 1)
+	a.Equal(BasisInferred, results[0].Basis)
+	a.Equal(hashA.CommitHash, results[0].GitTag.CommitHash)
+	a.Nil(results[0].TaggedAt)
+	a.Contains(results[0].Reason, "last observed at")
+
+	// deleted upstream
+	_, err = resolver.ResolveAsOf(ctx, repo, "v1.0.0", base.Add(30*time.Minute))
+	a.Error(err)
+
+	// from a hash
+	results, err = resolver.ResolveAsOf(ctx, repo, hashA.CommitHash, base.Add(5*time.Minute))
+	r.NoError(err)
+	r.Len(results, 2)
+	a.Equal("v1", results[0].GitTag.Tag)
+	a.Equal("v1.0.0", results[1].GitTag.Tag)
+
+	results, err = resolver.ResolveAsOf(ctx, repo, hashB.CommitHash, base.Add(30*time.Minute))
+	r.NoError(err)
+	r.Len(results, 2)
+	a.Equal("v1", results[0].GitTag.Tag)
+	a.Equal("v1.1.0", results[1].GitTag.Tag)
+}
@@ -1,17 +1,30 @@
 package resolver
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-const extensionName = "gh-taghash"
+const (
+	extensionName = "gh-taghash"
+
+	// gitDescribeExtensionName is the name of the cache directory used by the git-describe executor
+	gitDescribeExtensionName = "gh-git-describe"
+)
 
 type CacheTTL struct {
 	GitAliasTagTTL time.Duration
+	GitBranchTTL   time.Duration
 	GitFileTTL     time.Duration
 	GitTagTTL      time.Duration
 	QueryTTL       time.Duration
@@ -24,16 +37,21 @@ func NewCacheTTL(gitTagTTL time.Duration) *CacheTTL {
 	// set a shorter TTL for alias tags because it is more likely to be updated
 	gitAliasTagTTL := gitTagTTL / 8
 
+	// set a shorter TTL for branches and the other refs because they move with every push
+	gitBranchTTL := gitTagTTL / 8
+
 	return &CacheTTL{
 		GitFileTTL:     gitFileTTL,
 		GitTagTTL:      gitTagTTL,
 		GitAliasTagTTL: gitAliasTagTTL,
+		GitBranchTTL:   gitBranchTTL,
 		QueryTTL:       queryTTL,
 	}
 }
 
 func (t CacheTTL) String() string {
-	return fmt.Sprintf("{tag-alias=%s, git=%s, tag=%s, query=%s}", t.GitAliasTagTTL, t.GitFileTTL, t.GitTagTTL, t.QueryTTL)
+	return fmt.Sprintf("{tag-alias=%s, branch=%s, git=%s, tag=%s, query=%s}",
+		t.GitAliasTagTTL, t.GitBranchTTL, t.GitFileTTL, t.GitTagTTL, t.QueryTTL)
 }
 
 // ParseCacheTTL parses a cache TTL string and returns a CacheTTL.
@@ -68,3 +86,309 @@ func makeCacheDir(dirPath string, dirPerm os.FileMode) (string, error) {
 
 	return dirPath, nil
 }
+
+// touchRepo updates the last access time of a repository
+func (r *Resolver) touchRepo(ctx context.Context, repoID string, now time.Time) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "repo_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"last_accessed_at": now,
+			"updated_at":       now,
+		}),
+	}).Create(&GitRepo{
+		RepoID:         repoID,
+		LastAccessedAt: now,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update the last access time of %s: %w", repoID, result.Error)
+	}
+
+	return nil
+}
+
+// deleteRepoRecords physically deletes the records of a repository
+func deleteRepoRecords(tx *gorm.DB, repoID string) (int64, error) {
+	result := tx.Unscoped().Where(&GitTag{RepoID: repoID}).Delete(&GitTag{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete records: %w", result.Error)
+	}
+
+	refResult := tx.Where(&GitRef{RepoID: repoID}).Delete(&GitRef{})
+	if refResult.Error != nil {
+		return result.RowsAffected, fmt.Errorf("failed to delete ref records: %w", refResult.Error)
+	}
+
+	if err := tx.Where(&GitRepo{RepoID: repoID}).Delete(&GitRepo{}).Error; err != nil {
+		return result.RowsAffected + refResult.RowsAffected, fmt.Errorf("failed to delete repository records: %w", err)
+	}
+
+	return result.RowsAffected + refResult.RowsAffected, nil
+}
+
+func (r *Resolver) shouldVacuum(deletedCount int64) bool {
+	return r.vacuumThreshold > 0 && deletedCount >= r.vacuumThreshold
+}
+
+// Vacuum rebuilds the cache database file to reclaim the unused space.
+func (r *Resolver) Vacuum(ctx context.Context) error {
+	r.logger.Debug("vacuuming the cache database")
+
+	if err := r.db.WithContext(ctx).Exec("VACUUM").Error; err != nil {
+		return fmt.Errorf("failed to vacuum the cache database: %w", err)
+	}
+
+	return nil
+}
+
+// cacheDBUsedSize returns the size of the pages in use of the cache database in bytes.
+// Free pages are excluded because they are reused or reclaimed by VACUUM.
+func (r *Resolver) cacheDBUsedSize(ctx context.Context) (int64, error) {
+	var pageCount, freelistCount, pageSize int64
+	db := r.db.WithContext(ctx)
+
+	if err := db.Raw("PRAGMA page_count").Scan(&pageCount).Error; err != nil {
+		return 0, fmt.Errorf("failed to get the page count: %w", err)
+	}
+	if err := db.Raw("PRAGMA freelist_count").Scan(&freelistCount).Error; err != nil {
+		return 0, fmt.Errorf("failed to get the freelist count: %w", err)
+	}
+	if err := db.Raw("PRAGMA page_size").Scan(&pageSize).Error; err != nil {
+		return 0, fmt.Errorf("failed to get the page size: %w", err)
+	}
+
+	return (pageCount - freelistCount) * pageSize, nil
+}
+
+// evictCache deletes the records of the least recently accessed repositories
+// until the used size of the cache database gets below the MaxCacheSize.
+// The most recently accessed repository is never evicted.
+// It returns the number of the deleted records.
+func (r *Resolver) evictCache(ctx context.Context) (int64, error) {
+	if r.maxCacheSize <= 0 {
+		return 0, nil
+	}
+
+	usedSize, err := r.cacheDBUsedSize(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if usedSize <= r.maxCacheSize {
+		return 0, nil
+	}
+
+	// the repositories that have cached records are the candidates.
+	// the tag histories are not evicted to keep detecting the moved tags.
+	// repositories without the access information are evicted first.
+	var repoIDs []string
+	err = r.db.WithContext(ctx).Raw(`SELECT c.repo_id FROM (
+			SELECT repo_id FROM git_tags
+			UNION SELECT repo_id FROM git_refs
+			UNION SELECT repo_id FROM git_repos
+		) AS c
+		LEFT JOIN git_repos AS g ON g.repo_id = c.repo_id
+		ORDER BY COALESCE(g.last_accessed_at, '') ASC, c.repo_id ASC`).Scan(&repoIDs).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to select repositories: %w", err)
+	}
+
+	var evictedCount int64
+
+	for i := 0; i < len(repoIDs)-1 && usedSize > r.maxCacheSize; i++ {
+		repoID := repoIDs[i]
+
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			deletedCount, err := deleteRepoRecords(tx, repoID)
+			evictedCount += deletedCount
+
+			return err
+		})
+		if err != nil {
+			return evictedCount, fmt.Errorf("failed to evict %s: %w", repoID, err)
+		}
+
+		r.logger.Debug("evicted a repository from the cache database",
+			slog.String("repo", repoID),
+			slog.Int64("usedSize", usedSize),
+			slog.Int64("maxSize", r.maxCacheSize),
+		)
+
+		usedSize, err = r.cacheDBUsedSize(ctx)
+		if err != nil {
+			return evictedCount, err
+		}
+	}
+
+	return evictedCount, nil
+}
+
+// RepoCacheStats represents the cache statistics of a repository
+type RepoCacheStats struct {
+	// RepoID is the GitHub repository ID formatted as "owner/name"
+	RepoID string `json:"repoId"`
+
+	// RecordCount is the number of the cached records
+	RecordCount int64 `json:"recordCount"`
+
+	// ExpiredCount is the number of the expired records
+	ExpiredCount int64 `json:"expiredCount"`
+
+	// LastAccessedAt is the time when the repository is resolved last time
+	LastAccessedAt *time.Time `json:"lastAccessedAt,omitempty"`
+}
+
+// CacheStats represents the statistics of the cache
+type CacheStats struct {
+	// DBPath is the path to the cache database
+	DBPath string `json:"dbPath"`
+
+	// DBSize is the size of the cache database file in bytes
+	DBSize int64 `json:"dbSize"`
+
+	// GitCacheDirPath is the path to the cache directory of the cloned repositories
+	GitCacheDirPath string `json:"gitCacheDirPath"`
+
+	// GitCacheSize is the total size of the cloned repositories in bytes
+	GitCacheSize int64 `json:"gitCacheSize"`
+
+	// RecordCount is the number of the cached records
+	RecordCount int64 `json:"recordCount"`
+
+	// ExpiredCount is the number of the expired records
+	ExpiredCount int64 `json:"expiredCount"`
+
+	// SoftDeletedCount is the number of the soft-deleted records
+	SoftDeletedCount int64 `json:"softDeletedCount"`
+
+	// Repos is the cache statistics per repository
+	Repos []RepoCacheStats `json:"repos"`
+}
+
+func dirSize(dirPath string) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(dirPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			size += info.Size()
+		}
+
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// CacheDirPath returns the path to the cache directory
+func (r Resolver) CacheDirPath() string {
+	return r.cacheDirPath
+}
+
+// GitCacheDirPath returns the path to the cache directory of the cloned repositories.
+func (r Resolver) GitCacheDirPath() string {
+	return filepath.Join(filepath.Dir(r.cacheDirPath), gitDescribeExtensionName)
+}
+
+// CacheStats returns the statistics of the cache
+func (r Resolver) CacheStats(ctx context.Context) (*CacheStats, error) {
+	stats := &CacheStats{
+		DBPath:          r.cacheDBPath,
+		GitCacheDirPath: r.GitCacheDirPath(),
+		Repos:           []RepoCacheStats{},
+	}
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Raw(`SELECT t.repo_id AS repo_id,
+				COUNT(*) AS record_count,
+				SUM(CASE WHEN t.expired_at < ? THEN 1 ELSE 0 END) AS expired_count,
+				g.last_accessed_at AS last_accessed_at
+			FROM git_tags AS t
+			LEFT JOIN git_repos AS g ON g.repo_id = t.repo_id
+			WHERE t.deleted_at IS NULL
+			GROUP BY t.repo_id
+			ORDER BY t.repo_id`, now).Scan(&stats.Repos)
+		if result.Error != nil {
+			return fmt.Errorf("failed to count records: %w", result.Error)
+		}
+
+		result = tx.Unscoped().Model(&GitTag{}).Where(whereSoftDeleted).Count(&stats.SoftDeletedCount)
+		if result.Error != nil {
+			return fmt.Errorf("failed to count soft-deleted records: %w", result.Error)
+		}
+
+		return nil
+	}, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, repoStats := range stats.Repos {
+		stats.RecordCount += repoStats.RecordCount
+		stats.ExpiredCount += repoStats.ExpiredCount
+	}
+
+	info, err := os.Stat(r.cacheDBPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the information of the cache database: %w", err)
+	}
+	stats.DBSize = info.Size()
+
+	stats.GitCacheSize, err = dirSize(stats.GitCacheDirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the size of the git cache: %w", err)
+	}
+
+	return stats, nil
+}
+
+// ListCache returns the cached records of a repository including expired ones
+func (r Resolver) ListCache(ctx context.Context, repo repository.Repository) ([]GitTag, error) {
+	var gitTags []GitTag
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Where(&GitTag{RepoID: ToRepoID(repo)}).Order("tag").Find(&gitTags).Error
+	}, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to select records: %w", err)
+	}
+
+	return gitTags, nil
+}
+
+// ClearRepoCache deletes the cached records of a repository.
+// It returns the number of the deleted records.
+func (r *Resolver) ClearRepoCache(ctx context.Context, repo repository.Repository) (int64, error) {
+	var deletedCount int64
+	repoID := ToRepoID(repo)
+
+	r.logger.Debug("deleting the cache records", slog.String("repo", repoID))
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		deletedCount, err = deleteRepoRecords(tx, repoID)
+
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear the cache of %s: %w", repoID, err)
+	}
+
+	if r.shouldVacuum(deletedCount) {
+		if err := r.Vacuum(ctx); err != nil {
+			return deletedCount, err
+		}
+	}
+
+	return deletedCount, nil
+}
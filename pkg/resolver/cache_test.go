@@ -1,9 +1,11 @@
 package resolver
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/cli/go-gh/v2/pkg/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,3 +24,145 @@ func TestParseCacheTTL(t *testing.T) {
 	a.Equal(time.Hour, got.GitTagTTL)
 	a.Equal(30*time.Minute, got.QueryTTL)
 }
+
+func TestResolver_PruneCache(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	resolver := newTestResolver(t, &Params{VacuumThreshold: 1})
+	now := time.Now()
+
+	r.NoError(resolver.db.Create(&[]GitTag{
+		{RepoID: "owner/live", Tag: "v1.0.0", ExpiredAt: now.Add(time.Hour)},
+		{RepoID: "owner/expired", Tag: "v1.0.0", ExpiredAt: now.Add(-time.Hour)},
+		{RepoID: "owner/deleted", Tag: "v1.0.0", ExpiredAt: now.Add(time.Hour)},
+	}).Error)
+	r.NoError(resolver.db.Where(&GitTag{RepoID: "owner/deleted"}).Delete(&GitTag{}).Error)
+	for _, repoID := range []string{"owner/live", "owner/expired", "owner/deleted"} {
+		r.NoError(resolver.touchRepo(ctx, repoID, now))
+	}
+
+	r.NoError(resolver.db.Create(&GitRef{RepoID: "owner/live", Ref: "refs/heads/main", ExpiredAt: now.Add(-time.Hour)}).Error)
+
+	deletedCount, err := resolver.PruneCache(ctx, &now)
+	r.NoError(err)
+	a.Equal(int64(3), deletedCount, "the expired tag, the soft-deleted tag and the expired ref")
+
+	var gitTags []GitTag
+	r.NoError(resolver.db.Unscoped().Find(&gitTags).Error)
+	r.Len(gitTags, 1)
+	a.Equal("owner/live", gitTags[0].RepoID)
+
+	var gitRepos []GitRepo
+	r.NoError(resolver.db.Find(&gitRepos).Error)
+	r.Len(gitRepos, 1)
+	a.Equal("owner/live", gitRepos[0].RepoID)
+}
+
+func TestResolver_evictCache(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	resolver := newTestResolver(t, &Params{MaxCacheSize: 1})
+	now := time.Now()
+	expiredAt := now.Add(time.Hour)
+
+	repoIDs := []string{"owner/old", "owner/middle", "owner/new"}
+	for i, repoID := range repoIDs {
+		r.NoError(resolver.db.Create(&GitTag{RepoID: repoID, Tag: "v1.0.0", ExpiredAt: expiredAt}).Error)
+		r.NoError(resolver.touchRepo(ctx, repoID, now.Add(time.Duration(i)*time.Minute)))
+	}
+	r.NoError(resolver.db.Create(&GitTagHistory{RepoID: "owner/old", Tag: "v1.0.0"}).Error)
+
+	// the repositories that have no tag record
+	r.NoError(resolver.db.Create(&GitRef{RepoID: "owner/ref", Ref: "refs/heads/main", ExpiredAt: expiredAt}).Error)
+	r.NoError(resolver.touchRepo(ctx, "owner/ref", now.Add(-time.Minute)))
+	r.NoError(resolver.touchRepo(ctx, "owner/accessed", now.Add(-time.Minute)))
+
+	evictedCount, err := resolver.evictCache(ctx)
+	r.NoError(err)
+	a.Equal(int64(3), evictedCount)
+
+	var gitTags []GitTag
+	r.NoError(resolver.db.Unscoped().Find(&gitTags).Error)
+	r.Len(gitTags, 1)
+	a.Equal("owner/new", gitTags[0].RepoID)
+
+	var gitRefCount int64
+	r.NoError(resolver.db.Model(&GitRef{}).Count(&gitRefCount).Error)
+	a.Zero(gitRefCount)
+
+	// the tag histories are not evicted
+	var historyCount int64
+	r.NoError(resolver.db.Model(&GitTagHistory{}).Count(&historyCount).Error)
+	a.Equal(int64(1), historyCount)
+
+	var gitRepos []GitRepo
+	r.NoError(resolver.db.Find(&gitRepos).Error)
+	r.Len(gitRepos, 1)
+	a.Equal("owner/new", gitRepos[0].RepoID)
+}
+
+func TestResolver_CacheStats(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	resolver := newTestResolver(t, nil)
+	now := time.Now()
+
+	r.NoError(resolver.db.Create(&[]GitTag{
+		{RepoID: "owner/a", Tag: "v1.0.0", ExpiredAt: now.Add(time.Hour)},
+		{RepoID: "owner/a", Tag: "v0.9.0", ExpiredAt: now.Add(-time.Hour)},
+		{RepoID: "owner/b", Tag: "v1.0.0", ExpiredAt: now.Add(time.Hour)},
+	}).Error)
+	r.NoError(resolver.touchRepo(ctx, "owner/a", now))
+
+	stats, err := resolver.CacheStats(ctx)
+	r.NoError(err)
+	a.Equal(int64(3), stats.RecordCount)
+	a.Equal(int64(1), stats.ExpiredCount)
+	a.Equal(int64(0), stats.SoftDeletedCount)
+	a.Positive(stats.DBSize)
+	r.Len(stats.Repos, 2)
+
+	a.Equal("owner/a", stats.Repos[0].RepoID)
+	a.Equal(int64(2), stats.Repos[0].RecordCount)
+	a.Equal(int64(1), stats.Repos[0].ExpiredCount)
+	r.NotNil(stats.Repos[0].LastAccessedAt)
+	a.True(now.Equal(*stats.Repos[0].LastAccessedAt))
+
+	a.Equal("owner/b", stats.Repos[1].RepoID)
+	a.Nil(stats.Repos[1].LastAccessedAt)
+}
+
+func TestResolver_ClearRepoCache(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	resolver := newTestResolver(t, nil)
+	expiredAt := time.Now().Add(time.Hour)
+	repoA := repository.Repository{Owner: "owner", Name: "a"}
+	repoB := repository.Repository{Owner: "owner", Name: "b"}
+
+	r.NoError(resolver.db.Create(&[]GitTag{
+		{RepoID: ToRepoID(repoA), Tag: "v1.0.0", ExpiredAt: expiredAt},
+		{RepoID: ToRepoID(repoA), Tag: "v2.0.0", ExpiredAt: expiredAt},
+		{RepoID: ToRepoID(repoB), Tag: "v1.0.0", ExpiredAt: expiredAt},
+	}).Error)
+
+	deletedCount, err := resolver.ClearRepoCache(ctx, repoA)
+	r.NoError(err)
+	a.Equal(int64(2), deletedCount)
+
+	gitTags, err := resolver.ListCache(ctx, repoA)
+	r.NoError(err)
+	a.Empty(gitTags)
+
+	gitTags, err = resolver.ListCache(ctx, repoB)
+	r.NoError(err)
+	a.Len(gitTags, 1)
+}
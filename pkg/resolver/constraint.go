@@ -0,0 +1,296 @@
+package resolver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// latestKeyword is the version query that matches any version
+const latestKeyword = "latest"
+
+var (
+	constraintOperators   = []string{">=", "<=", ">", "<", "=", "^", "~"}
+	partialVersionRegexp  = regexp.MustCompile(`^[vV]?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+	lowestPrereleaseLabel = "0"
+)
+
+// comparator is a single comparison such as ">=1.2.0"
+type comparator struct {
+	op      string
+	version Version
+}
+
+func (c comparator) match(v Version) bool {
+	cmp := v.Compare(c.version)
+
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp == 0
+	}
+}
+
+// VersionConstraint represents a semantic version range such as "^4", "~4.1" or ">=4.1.0 <5".
+// Ranges are combined with "||", and comparators separated by spaces must all be satisfied.
+// A path prefix of monorepo tags can be specified before the range (e.g. "sdk/go/^1").
+type VersionConstraint struct {
+	// Query is the original query string
+	Query string
+
+	// Prefix is the path prefix of the tags to match including the trailing "/"
+	Prefix string
+
+	// ranges is the alternatives of the comparators to satisfy
+	ranges [][]comparator
+}
+
+// IsVersionQuery returns true if a tag argument is a semantic version range or the "latest" keyword
+// rather than a literal tag name
+func IsVersionQuery(s string) bool {
+	_, query := splitPathPrefix(strings.TrimSpace(s))
+	if query == latestKeyword {
+		return true
+	}
+
+	if strings.ContainsAny(query, " |") {
+		return true
+	}
+
+	for _, op := range constraintOperators {
+		if strings.HasPrefix(query, op) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseVersionConstraint parses a semantic version range or the "latest" keyword
+func ParseVersionConstraint(query string) (*VersionConstraint, error) {
+	prefix, rangesStr := splitPathPrefix(strings.TrimSpace(query))
+
+	c := &VersionConstraint{
+		Query:  query,
+		Prefix: prefix,
+	}
+
+	if rangesStr == latestKeyword {
+		c.ranges = [][]comparator{{}}
+		return c, nil
+	}
+
+	for _, rangeStr := range strings.Split(rangesStr, "||") {
+		comparators, err := parseRange(rangeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint (%s): %w", query, err)
+		}
+
+		c.ranges = append(c.ranges, comparators)
+	}
+
+	return c, nil
+}
+
+// Match returns true if a version satisfies the constraint
+func (c VersionConstraint) Match(v Version) bool {
+	if v.Prefix != c.Prefix {
+		return false
+	}
+
+	for _, comparators := range c.ranges {
+		matched := true
+		for _, comp := range comparators {
+			if !comp.match(v) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseRange(rangeStr string) ([]comparator, error) {
+	fields := strings.Fields(rangeStr)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty range")
+	}
+
+	comparators := []comparator{}
+
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+
+		op := ""
+		for _, candidate := range constraintOperators {
+			if strings.HasPrefix(field, candidate) {
+				op = candidate
+				break
+			}
+		}
+
+		versionStr := strings.TrimPrefix(field, op)
+		if versionStr == "" && i+1 < len(fields) {
+			// allow a space between an operator and a version (e.g. ">= 1.2.0")
+			i++
+			versionStr = fields[i]
+		}
+
+		comps, err := expandComparator(op, versionStr)
+		if err != nil {
+			return nil, err
+		}
+
+		comparators = append(comparators, comps...)
+	}
+
+	return comparators, nil
+}
+
+// partialVersion is a version that may lack components or have wildcards (e.g. "4", "4.1.x")
+type partialVersion struct {
+	major, minor, patch uint64
+	prerelease          string
+
+	// parts is the number of the components before the first missing component or wildcard
+	parts int
+}
+
+func parsePartialVersion(s string) (*partialVersion, error) {
+	matches := partialVersionRegexp.FindStringSubmatch(s)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid version: %s", s)
+	}
+
+	pv := &partialVersion{prerelease: matches[4]}
+
+	for i, dst := range []*uint64{&pv.major, &pv.minor, &pv.patch} {
+		component := matches[i+1]
+		if component == "" || strings.ContainsAny(component, "xX*") {
+			break
+		}
+
+		n, err := strconv.ParseUint(component, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version: %s: %w", s, err)
+		}
+
+		*dst = n
+		pv.parts++
+	}
+
+	return pv, nil
+}
+
+func (pv partialVersion) lower() Version {
+	return Version{Major: pv.major, Minor: pv.minor, Patch: pv.patch, Prerelease: pv.prerelease, Parts: 3}
+}
+
+// upperBound returns the lowest version that is excluded from the range.
+// The lowest pre-release label is used not to match the pre-releases of the upper bound.
+func upperBound(major, minor, patch uint64) Version {
+	return Version{Major: major, Minor: minor, Patch: patch, Prerelease: lowestPrereleaseLabel, Parts: 3}
+}
+
+// nextUpper returns the upper bound of the components of a partial version (e.g. "4.1" -> "<4.2.0-0")
+func (pv partialVersion) nextUpper() Version {
+	switch pv.parts {
+	case 1:
+		return upperBound(pv.major+1, 0, 0)
+	case 2:
+		return upperBound(pv.major, pv.minor+1, 0)
+	default:
+		return upperBound(pv.major, pv.minor, pv.patch+1)
+	}
+}
+
+func expandComparator(op, versionStr string) ([]comparator, error) {
+	if versionStr == "" {
+		return nil, fmt.Errorf("missing a version after the operator: %s", op)
+	}
+
+	pv, err := parsePartialVersion(versionStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// a wildcard matches any version
+	if pv.parts == 0 {
+		if op == ">" || op == "<" {
+			return nil, fmt.Errorf("the operator cannot be used with a wildcard: %s%s", op, versionStr)
+		}
+
+		return []comparator{}, nil
+	}
+
+	switch op {
+	case "", "=":
+		if pv.parts == 3 {
+			return []comparator{{op: "=", version: pv.lower()}}, nil
+		}
+
+		return []comparator{{op: ">=", version: pv.lower()}, {op: "<", version: pv.nextUpper()}}, nil
+
+	case "^":
+		var upper Version
+		switch {
+		case pv.major > 0 || pv.parts == 1:
+			upper = upperBound(pv.major+1, 0, 0)
+		case pv.minor > 0 || pv.parts == 2:
+			upper = upperBound(0, pv.minor+1, 0)
+		default:
+			upper = upperBound(0, 0, pv.patch+1)
+		}
+
+		return []comparator{{op: ">=", version: pv.lower()}, {op: "<", version: upper}}, nil
+
+	case "~":
+		upper := upperBound(pv.major, pv.minor+1, 0)
+		if pv.parts == 1 {
+			upper = upperBound(pv.major+1, 0, 0)
+		}
+
+		return []comparator{{op: ">=", version: pv.lower()}, {op: "<", version: upper}}, nil
+
+	case ">":
+		if pv.parts == 3 {
+			return []comparator{{op: ">", version: pv.lower()}}, nil
+		}
+
+		return []comparator{{op: ">=", version: pv.nextUpper()}}, nil
+
+	case ">=":
+		return []comparator{{op: ">=", version: pv.lower()}}, nil
+
+	case "<":
+		lower := pv.lower()
+		if pv.parts < 3 || lower.Prerelease == "" {
+			lower.Prerelease = lowestPrereleaseLabel
+		}
+
+		return []comparator{{op: "<", version: lower}}, nil
+
+	case "<=":
+		if pv.parts == 3 {
+			return []comparator{{op: "<=", version: pv.lower()}}, nil
+		}
+
+		return []comparator{{op: "<", version: pv.nextUpper()}}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", op)
+	}
+}
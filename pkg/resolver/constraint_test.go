@@ -0,0 +1,104 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsVersionQuery(t *testing.T) {
+	a := assert.New(t)
+
+	for _, value := range []string{"latest", "^4", "~4.1", ">=4.1.0 <5", "sdk/go/^1", "sdk/go/latest", "^1 || ^2"} {
+		a.True(IsVersionQuery(value), value)
+	}
+
+	for _, value := range []string{"v4", "v4.1.6", "sdk/go/v1.2.3", "main", "0123456789abcdef0123456789abcdef01234567"} {
+		a.False(IsVersionQuery(value), value)
+	}
+}
+
+func TestVersionConstraint_Match(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	testCases := []struct {
+		query    string
+		matches  []string
+		excludes []string
+	}{
+		{
+			query:    "latest",
+			matches:  []string{"v0.0.1", "v4.1.6", "v5.0.0-rc.1"},
+			excludes: []string{"sdk/go/v1.2.3"},
+		},
+		{
+			query:    "^4",
+			matches:  []string{"v4.0.0", "v4.1.6", "4.99.0"},
+			excludes: []string{"v3.9.9", "v5.0.0", "v5.0.0-rc.1", "v4.0.0-rc.1"},
+		},
+		{
+			query:    "^0.2.3",
+			matches:  []string{"v0.2.3", "v0.2.9"},
+			excludes: []string{"v0.2.2", "v0.3.0"},
+		},
+		{
+			query:    "~4.1",
+			matches:  []string{"v4.1.0", "v4.1.6"},
+			excludes: []string{"v4.0.9", "v4.2.0"},
+		},
+		{
+			query:    "~4.1.2",
+			matches:  []string{"v4.1.2", "v4.1.6"},
+			excludes: []string{"v4.1.1", "v4.2.0"},
+		},
+		{
+			query:    ">=4.1.0 <5",
+			matches:  []string{"v4.1.0", "v4.9.9"},
+			excludes: []string{"v4.0.9", "v5.0.0", "v5.0.0-beta"},
+		},
+		{
+			query:    ">= 1.2.0 <= 1.3",
+			matches:  []string{"v1.2.0", "v1.3.9"},
+			excludes: []string{"v1.1.9", "v1.4.0"},
+		},
+		{
+			query:    "^1 || ^3",
+			matches:  []string{"v1.0.0", "v3.2.1"},
+			excludes: []string{"v2.0.0"},
+		},
+		{
+			query:    "4.x",
+			matches:  []string{"v4.0.0", "v4.5.6"},
+			excludes: []string{"v5.0.0"},
+		},
+		{
+			query:    "sdk/go/^1.2",
+			matches:  []string{"sdk/go/v1.2.3", "sdk/go/v1.9.0"},
+			excludes: []string{"v1.2.3", "sdk/python/v1.2.3", "sdk/go/v2.0.0"},
+		},
+	}
+
+	for _, tc := range testCases {
+		c, err := ParseVersionConstraint(tc.query)
+		r.NoError(err, tc.query)
+
+		for _, tag := range tc.matches {
+			v, err := ParseVersion(tag)
+			r.NoError(err, tag)
+			a.True(c.Match(*v), "%s should match %s", tc.query, tag)
+		}
+
+		for _, tag := range tc.excludes {
+			v, err := ParseVersion(tag)
+			r.NoError(err, tag)
+			a.False(c.Match(*v), "%s should not match %s", tc.query, tag)
+		}
+	}
+
+	for _, query := range []string{">=", "^foo", "<*"} {
+		_, err := ParseVersionConstraint(query)
+		a.Error(err, query)
+	}
+}
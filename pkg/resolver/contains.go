@@ -0,0 +1,110 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	gitdescribe "github.com/thombashi/gh-git-describe/pkg/executor"
+)
+
+// validateRevision rejects a revision that may be interpreted as a git option
+func validateRevision(rev string) error {
+	if rev == "" {
+		return fmt.Errorf("require a revision")
+	}
+
+	if strings.HasPrefix(rev, "-") {
+		return fmt.Errorf("invalid revision: %s", rev)
+	}
+
+	return nil
+}
+
+// resolveCommitFromGitObj resolves a revision to the full commit hash in the git clone cache
+func (r Resolver) resolveCommitFromGitObj(ctx context.Context, repoID, rev string) (string, error) {
+	if err := validateRevision(rev); err != nil {
+		return "", err
+	}
+
+	commitHash, err := r.gdExecutor.RunGitRevParseContext(ctx, &gitdescribe.RepoCloneParams{
+		RepoID:   repoID,
+		CacheTTL: r.cacheTTL.GitFileTTL,
+	}, "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("the commit is not found in the repository: %s: %w", rev, err)
+	}
+
+	return commitHash, nil
+}
+
+// TagsContaining lists the tags whose history includes the commit, like 'git tag --contains'.
+// The tags are sorted by the semantic versions in descending order,
+// and the tags that are not semantic versions follow in the order of the names.
+// It uses the git clone cache of the repository.
+func (r Resolver) TagsContaining(ctx context.Context, repo repository.Repository, commit string) ([]string, error) {
+	repoID := ToRepoID(repo)
+
+	commitHash, err := r.resolveCommitFromGitObj(ctx, repoID, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug("listing tags containing a commit", slog.String("repo", repoID), slog.String("commit", commitHash))
+
+	output, err := r.gdExecutor.RunGitContext(ctx, &gitdescribe.RepoCloneParams{
+		RepoID:   repoID,
+		CacheTTL: r.cacheTTL.GitFileTTL,
+	}, "tag", "--contains", commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if tag := strings.TrimSpace(line); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	sortTagsBySemver(tags)
+
+	return tags, nil
+}
+
+// TagContains returns true if the history of the tag includes the commit.
+// It uses the git clone cache of the repository.
+func (r Resolver) TagContains(ctx context.Context, repo repository.Repository, tag, commit string) (bool, error) {
+	repoID := ToRepoID(repo)
+
+	if err := validateRevision(tag); err != nil {
+		return false, err
+	}
+
+	commitHash, err := r.resolveCommitFromGitObj(ctx, repoID, commit)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := r.resolveCommitFromGitObj(ctx, repoID, "refs/tags/"+tag); err != nil {
+		return false, fmt.Errorf("the tag is not found in the repository: %s", tag)
+	}
+
+	r.logger.Debug("checking whether a tag contains a commit",
+		slog.String("repo", repoID),
+		slog.String("tag", tag),
+		slog.String("commit", commitHash))
+
+	// list the tag only if it contains the commit
+	output, err := r.gdExecutor.RunGitContext(ctx, &gitdescribe.RepoCloneParams{
+		RepoID:   repoID,
+		CacheTTL: r.cacheTTL.GitFileTTL,
+	}, "tag", "--list", "--contains", commitHash, tag)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(output) == tag, nil
+}
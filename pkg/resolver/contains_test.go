@@ -0,0 +1,55 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_TagsContaining(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	gitRepo := newTestGitRepo(t)
+	gitRepo.commit("initial")
+	gitRepo.git("tag", "v1.0.0")
+	fix := gitRepo.commit("fix")
+	gitRepo.git("tag", "v1.0.1")
+	gitRepo.commit("feature")
+	gitRepo.git("tag", "-a", "-m", "v1.10.0", "v1.10.0")
+	gitRepo.git("tag", "v1")
+	gitRepo.git("tag", "nightly")
+
+	gitRepo.git("checkout", "--quiet", "-b", "release-1.0", "v1.0.0")
+	gitRepo.commit("backport without the fix")
+	gitRepo.git("tag", "v1.0.2")
+
+	resolver := newTestResolver(t, &Params{GitDescExecutor: gitRepo.executor()})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	tags, err := resolver.TagsContaining(ctx, repo, fix)
+	r.NoError(err)
+	a.Equal([]string{"v1.10.0", "v1.0.1", "v1", "nightly"}, tags)
+
+	tags, err = resolver.TagsContaining(ctx, repo, fix[:7])
+	r.NoError(err)
+	a.Len(tags, 4)
+
+	contains, err := resolver.TagContains(ctx, repo, "v1.10.0", fix)
+	r.NoError(err)
+	a.True(contains)
+
+	contains, err = resolver.TagContains(ctx, repo, "v1.0.2", fix)
+	r.NoError(err)
+	a.False(contains)
+
+	_, err = resolver.TagContains(ctx, repo, "v9.9.9", fix)
+	a.Error(err)
+
+	_, err = resolver.TagsContaining(ctx, repo, "--all")
+	a.Error(err)
+}
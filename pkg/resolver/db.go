@@ -8,11 +8,44 @@ import (
 )
 
 const (
-	whereExpired    = "expired_at < ?"
-	whereNotExpired = "? <= expired_at"
+	whereExpired            = "expired_at < ?"
+	whereNotExpired         = "? <= expired_at"
+	whereSoftDeleted        = "deleted_at IS NOT NULL"
+	whereUpstreamExist      = "upstream_deleted_at IS NULL"
+	whereUpstreamDeleted    = "upstream_deleted_at IS NOT NULL"
+	whereDescribeKey        = "(tag = base_tag OR describe_key = ?)"
+	columnUpstreamDeletedAt = "upstream_deleted_at"
 )
 
-// GitRepo represents a GORM model for git tag data
+const (
+	// SourceGraphQL is the source of the records fetched via the GitHub GraphQL API
+	SourceGraphQL = "graphql"
+
+	// SourceGit is the source of the records resolved from the cloned git repository
+	SourceGit = "git"
+)
+
+// GitRepo represents a GORM model for the access information of a repository
+type GitRepo struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// RepoID is the GitHub repository ID formatted as "owner/name"
+	RepoID string `gorm:"uniqueIndex"`
+
+	// LastAccessedAt is the time when the repository is resolved last time.
+	// This is used to evict the least recently used repositories from the cache.
+	LastAccessedAt time.Time
+
+	// TagListExpiredAt is the time when the cached tag list of the repository is expired:
+	// the earliest expiry of the tags fetched together.
+	// The records of the alias tags expire earlier than the others, so the cached records are not
+	// a complete tag list after the time even if some of them are not expired.
+	TagListExpiredAt time.Time
+}
+
+// GitTag represents a GORM model for git tag data
 type GitTag struct {
 	gorm.Model
 	ID uint
@@ -32,10 +65,100 @@ type GitTag struct {
 	// TagHash is the git tag hash
 	TagHash string
 
+	// Source is where the record is resolved from (SourceGraphQL or SourceGit)
+	Source string
+
+	// DescribeKey is the key of the describe options (DescribeOptions.Key) used to resolve the record.
+	// Empty for the default options and the records that are not resolved by 'git describe'.
+	DescribeKey string
+
+	// FetchedAt is the time when the record is fetched from the source
+	FetchedAt time.Time
+
 	// ExpiredAt is the time when the record is expired
 	ExpiredAt time.Time
+
+	// UpstreamDeletedAt is the time when the tag is found deleted from the repository.
+	// nil if the tag exists.
+	UpstreamDeletedAt *time.Time
 }
 
 func (g GitTag) String() string {
 	return fmt.Sprintf("RepoID=%s, Tag=%s, CommitHash=%s, TagHash=%s", g.RepoID, g.Tag, g.CommitHash, g.TagHash)
 }
+
+// IsDeletedUpstream returns true if the tag is deleted from the repository
+func (g GitTag) IsDeletedUpstream() bool {
+	return g.UpstreamDeletedAt != nil
+}
+
+// TagDeletedError is returned when a tag is resolved but it is deleted from the repository
+type TagDeletedError struct {
+	// GitTag is the last known record of the deleted tag
+	GitTag GitTag
+}
+
+func (e *TagDeletedError) Error() string {
+	return fmt.Sprintf("the tag is deleted upstream: repo=%s, tag=%s, deletedAt=%s",
+		e.GitTag.RepoID, e.GitTag.Tag, e.GitTag.UpstreamDeletedAt.Format(time.RFC3339))
+}
+
+// GitTagHistory represents a GORM model for the observed history of a git tag.
+// A record is appended every time a tag is observed pointing to a new object.
+// The records are kept even if the cache is pruned, cleared or evicted.
+type GitTagHistory struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// RepoID is the GitHub repository ID formatted as "owner/name"
+	RepoID string `gorm:"index:idx_git_tag_histories_repo_tag"`
+
+	// Tag is the git tag name
+	Tag string `gorm:"index:idx_git_tag_histories_repo_tag"`
+
+	// CommitHash is the git commit hash that the tag points to
+	CommitHash string
+
+	// TagHash is the git tag hash
+	TagHash string
+
+	// FirstSeenAt is the time when the tag is observed pointing to the hashes for the first time
+	FirstSeenAt time.Time
+
+	// LastSeenAt is the time when the tag is observed pointing to the hashes for the last time
+	LastSeenAt time.Time
+
+	// UpstreamDeletedAt is the time when the tag is found deleted from the repository.
+	// nil if the tag exists.
+	UpstreamDeletedAt *time.Time
+}
+
+func (h GitTagHistory) hash() Hash {
+	return Hash{CommitHash: h.CommitHash, TagHash: h.TagHash}
+}
+
+// GitRef represents a GORM model for a git ref other than the tags, such as a branch or a pull request ref
+type GitRef struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// RepoID is the GitHub repository ID formatted as "owner/name"
+	RepoID string `gorm:"index:idx_git_refs_repo_ref"`
+
+	// Ref is the fully qualified ref name (e.g. refs/heads/main)
+	Ref string `gorm:"index:idx_git_refs_repo_ref"`
+
+	// Type is the type of the ref
+	Type RefType
+
+	// CommitHash is the git commit hash that the ref points to
+	CommitHash string
+
+	// FetchedAt is the time when the record is fetched from GitHub
+	FetchedAt time.Time
+
+	// ExpiredAt is the time when the record is expired
+	ExpiredAt time.Time
+}
@@ -0,0 +1,273 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	gitdescribe "github.com/thombashi/gh-git-describe/pkg/executor"
+)
+
+// describeRegexp matches the output of 'git describe' that is not an exact match: <tag>-<distance>-g<abbrev>
+var describeRegexp = regexp.MustCompile(`^(.+)-(\d+)-g([0-9a-f]{4,40})$`)
+
+// DescribeOptions represents the options of 'git describe' used in the fallback of hash-to-tag resolution
+type DescribeOptions struct {
+	// Match is the glob patterns of the tags to consider (--match)
+	Match []string
+
+	// Exclude is the glob patterns of the tags not to consider (--exclude)
+	Exclude []string
+
+	// FirstParent is a flag to follow only the first parent of merge commits (--first-parent)
+	FirstParent bool
+
+	// Long is a flag to always output the long format even for an exact match (--long)
+	Long bool
+
+	// Abbrev is the number of the hexadecimal digits of the abbreviated hash (--abbrev).
+	// Nil means the default of git.
+	Abbrev *int
+
+	// Always is a flag to output the abbreviated hash if no tag is found (--always)
+	Always bool
+}
+
+// filterArgs returns the arguments that select the tags to consider
+func (o DescribeOptions) filterArgs() []string {
+	args := []string{"--tags"}
+
+	for _, pattern := range o.Match {
+		args = append(args, "--match", pattern)
+	}
+	for _, pattern := range o.Exclude {
+		args = append(args, "--exclude", pattern)
+	}
+	if o.FirstParent {
+		args = append(args, "--first-parent")
+	}
+
+	return args
+}
+
+// args returns the arguments of 'git describe'
+func (o DescribeOptions) args() []string {
+	args := o.filterArgs()
+
+	if o.Long {
+		args = append(args, "--long")
+	}
+	if o.Abbrev != nil {
+		args = append(args, fmt.Sprintf("--abbrev=%d", *o.Abbrev))
+	}
+	if o.Always {
+		args = append(args, "--always")
+	}
+
+	return args
+}
+
+// Key returns a string that identifies the options.
+// The records resolved by 'git describe' are cached per key.
+// Empty for the default options.
+func (o DescribeOptions) Key() string {
+	args := o.args()[1:]
+
+	return strings.Join(args, " ")
+}
+
+// MatchTag returns true if a tag is a candidate under the --match and the --exclude patterns
+func (o DescribeOptions) MatchTag(tag string) bool {
+	if len(o.Match) > 0 {
+		matched := false
+		for _, pattern := range o.Match {
+			if matchGlob(pattern, tag) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	for _, pattern := range o.Exclude {
+		if matchGlob(pattern, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchGlob matches a name with a glob pattern in the same way as the --match option of 'git describe':
+// unlike path.Match, "*" matches "/" as well.
+func matchGlob(pattern, name string) bool {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			sb.WriteString(".*")
+
+		case '?':
+			sb.WriteString(".")
+
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+
+			sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(name)
+}
+
+// Describe represents a parsed result of 'git describe'
+type Describe struct {
+	// BaseTag is the nearest tag reachable from the commit
+	BaseTag string
+
+	// Distance is the number of the commits since the base tag
+	Distance int
+
+	// AbbrevHash is the abbreviated commit hash without the leading "g".
+	// Empty for an exact match.
+	AbbrevHash string
+
+	// ExactMatch is true if the commit is tagged with the base tag
+	ExactMatch bool
+}
+
+// ParseDescribe parses a describe-style string such as "v4.1.6-4-g6ccd57f".
+// It returns false if the string is not describe-style.
+func ParseDescribe(s string) (*Describe, bool) {
+	matches := describeRegexp.FindStringSubmatch(s)
+	if matches == nil {
+		return nil, false
+	}
+
+	distance, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return nil, false
+	}
+
+	return &Describe{
+		BaseTag:    matches[1],
+		Distance:   distance,
+		AbbrevHash: matches[3],
+		ExactMatch: distance == 0,
+	}, true
+}
+
+// Describe returns the parsed describe fields of the tag.
+// A tag resolved from the tag list is an exact match of itself.
+func (g GitTag) Describe() Describe {
+	if g.BaseTag == "" && g.Tag != "" && strings.HasPrefix(g.CommitHash, g.Tag) {
+		// the abbreviated hash that is output by 'git describe --always' if no tag is found
+		return Describe{AbbrevHash: g.Tag}
+	}
+
+	if g.BaseTag == "" || g.Tag == g.BaseTag {
+		return Describe{BaseTag: g.Tag, ExactMatch: true}
+	}
+
+	if d, ok := ParseDescribe(g.Tag); ok && d.BaseTag == g.BaseTag {
+		return *d
+	}
+
+	return Describe{BaseTag: g.BaseTag}
+}
+
+// DescribeMismatchError is returned when a describe-style string does not match the repository
+type DescribeMismatchError struct {
+	// Value is the describe-style string
+	Value string
+
+	// Reason is the explanation of the mismatch
+	Reason string
+}
+
+func (e *DescribeMismatchError) Error() string {
+	return fmt.Sprintf("the describe string does not match the repository: %s: %s", e.Value, e.Reason)
+}
+
+// verifyDescribe verifies that the abbreviated hash of a describe-style string is a commit
+// that is the distance ahead of the base tag, and returns the full commit hash.
+func (r Resolver) verifyDescribe(ctx context.Context, repoID, value string, d Describe) (string, error) {
+	r.logger.Debug("verifying a describe string", slog.String("repo", repoID), slog.String("value", value))
+
+	commitHash, err := r.resolveCommitFromGitObj(ctx, repoID, d.AbbrevHash)
+	if err != nil {
+		return "", &DescribeMismatchError{Value: value, Reason: fmt.Sprintf("the commit %s is not found", d.AbbrevHash)}
+	}
+
+	baseCommitHash, err := r.resolveCommitFromGitObj(ctx, repoID, "refs/tags/"+d.BaseTag)
+	if err != nil {
+		return "", &DescribeMismatchError{Value: value, Reason: fmt.Sprintf("the base tag %s is not found", d.BaseTag)}
+	}
+
+	cloneParams := &gitdescribe.RepoCloneParams{
+		RepoID:   repoID,
+		CacheTTL: r.cacheTTL.GitFileTTL,
+	}
+
+	mergeBase, err := r.gdExecutor.RunGitContext(ctx, cloneParams, "merge-base", baseCommitHash, commitHash)
+	if err != nil || strings.TrimSpace(mergeBase) != baseCommitHash {
+		return "", &DescribeMismatchError{
+			Value:  value,
+			Reason: fmt.Sprintf("the commit %s is not a descendant of %s", d.AbbrevHash, d.BaseTag),
+		}
+	}
+
+	count, err := r.gdExecutor.RunGitRevListContext(ctx, cloneParams, "--count", baseCommitHash+".."+commitHash)
+	if err != nil {
+		return "", err
+	}
+
+	distance, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the number of commits: %w", err)
+	}
+
+	if distance != d.Distance {
+		return "", &DescribeMismatchError{
+			Value:  value,
+			Reason: fmt.Sprintf("the commit %s is %d commits ahead of %s", d.AbbrevHash, distance, d.BaseTag),
+		}
+	}
+
+	return commitHash, nil
+}
@@ -0,0 +1,196 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDescribe(t *testing.T) {
+	a := assert.New(t)
+
+	testCases := []struct {
+		value string
+		want  *Describe
+	}{
+		{
+			value: "v4.1.6-4-g6ccd57f",
+			want:  &Describe{BaseTag: "v4.1.6", Distance: 4, AbbrevHash: "6ccd57f"},
+		},
+		{
+			value: "helm-chart-1.2.3-12-g0123456789ab",
+			want:  &Describe{BaseTag: "helm-chart-1.2.3", Distance: 12, AbbrevHash: "0123456789ab"},
+		},
+		{
+			value: "v1.0.0-0-gabcdef0",
+			want:  &Describe{BaseTag: "v1.0.0", Distance: 0, AbbrevHash: "abcdef0", ExactMatch: true},
+		},
+		{
+			value: "v4.1.6",
+		},
+		{
+			value: "v1.0.0-rc-1",
+		},
+	}
+
+	for _, tc := range testCases {
+		got, ok := ParseDescribe(tc.value)
+		if tc.want == nil {
+			a.False(ok, tc.value)
+			continue
+		}
+
+		a.True(ok, tc.value)
+		a.Equal(tc.want, got, tc.value)
+	}
+}
+
+func TestGitTag_Describe(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(Describe{BaseTag: "v4.1.6", ExactMatch: true}, GitTag{Tag: "v4.1.6", BaseTag: "v4.1.6"}.Describe())
+	a.Equal(
+		Describe{BaseTag: "v4.1.6", Distance: 4, AbbrevHash: "6ccd57f"},
+		GitTag{Tag: "v4.1.6-4-g6ccd57f", BaseTag: "v4.1.6"}.Describe(),
+	)
+}
+
+func TestResolver_verifyDescribe(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	gitRepo := newTestGitRepo(t)
+	gitRepo.commit("initial")
+	gitRepo.git("tag", "v1.0.0")
+	gitRepo.commit("second")
+	head := gitRepo.commit("third")
+	gitRepo.git("checkout", "--quiet", "--orphan", "orphan")
+	orphan := gitRepo.commit("orphan")
+
+	resolver := newTestResolver(t, &Params{GitDescExecutor: gitRepo.executor()})
+
+	value := fmt.Sprintf("v1.0.0-2-g%s", head[:7])
+	d, ok := ParseDescribe(value)
+	r.True(ok)
+
+	commitHash, err := resolver.verifyDescribe(ctx, "owner/a", value, *d)
+	r.NoError(err)
+	a.Equal(head, commitHash)
+
+	for _, value := range []string{
+		fmt.Sprintf("v1.0.0-3-g%s", head[:7]),
+		fmt.Sprintf("v9.9.9-2-g%s", head[:7]),
+		fmt.Sprintf("v1.0.0-1-g%s", orphan[:7]),
+		"v1.0.0-2-gffffff0",
+	} {
+		d, ok := ParseDescribe(value)
+		r.True(ok, value)
+
+		_, err := resolver.verifyDescribe(ctx, "owner/a", value, *d)
+		var mismatchErr *DescribeMismatchError
+		a.ErrorAs(err, &mismatchErr, value)
+	}
+}
+
+func TestDescribeOptions(t *testing.T) {
+	a := assert.New(t)
+
+	abbrev := 10
+	opts := DescribeOptions{
+		Match:       []string{"v*"},
+		Exclude:     []string{"*-rc*"},
+		FirstParent: true,
+		Long:        true,
+		Abbrev:      &abbrev,
+		Always:      true,
+	}
+
+	a.Equal([]string{"--tags", "--match", "v*", "--exclude", "*-rc*", "--first-parent", "--long", "--abbrev=10", "--always"}, opts.args())
+	a.Equal("--match v* --exclude *-rc* --first-parent --long --abbrev=10 --always", opts.Key())
+	a.Empty(DescribeOptions{}.Key())
+
+	a.True(opts.MatchTag("v1.0.0"))
+	a.False(opts.MatchTag("v1.0.0-rc.1"))
+	a.False(opts.MatchTag("helm-chart-1.0.0"))
+	a.True(DescribeOptions{}.MatchTag("helm-chart-1.0.0"))
+}
+
+func TestMatchGlob(t *testing.T) {
+	a := assert.New(t)
+
+	testCases := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "v*", name: "v1.0.0", want: true},
+		{pattern: "v*", name: "helm-chart-1.0.0", want: false},
+		{pattern: "sdk/*", name: "sdk/go/v1.0.0", want: true},
+		{pattern: "v?.0", name: "v1.0", want: true},
+		{pattern: "v[0-9].*", name: "v1.0", want: true},
+		{pattern: "v[!0-9]*", name: "v1.0", want: false},
+		{pattern: "v1.0", name: "v1x0", want: false},
+	}
+
+	for _, tc := range testCases {
+		a.Equal(tc.want, matchGlob(tc.pattern, tc.name), "%s %s", tc.pattern, tc.name)
+	}
+}
+
+func TestResolver_ResolveFromHashContext_DescribeOptions(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+	commitA := strings.Repeat("a", 40)
+	commitB := strings.Repeat("b", 40)
+	now := time.Now()
+
+	cacheDirPath := t.TempDir()
+	newResolver := func(opts DescribeOptions) *Resolver {
+		return newTestResolver(t, &Params{CacheDirPath: cacheDirPath, DescribeOptions: opts})
+	}
+
+	resolver := newResolver(DescribeOptions{})
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{
+		"v1.0.0":           {CommitHash: commitA, TagHash: commitA},
+		"helm-chart-1.0.0": {CommitHash: commitA, TagHash: commitA},
+	}, now))
+
+	// a record resolved by 'git describe' with the --long option
+	r.NoError(resolver.db.Create(&GitTag{
+		RepoID:      ToRepoID(repo),
+		Tag:         "v1.0.0-2-gbbbbbbbbbb",
+		BaseTag:     "v1.0.0",
+		CommitHash:  commitB,
+		TagHash:     commitB,
+		Source:      SourceGit,
+		DescribeKey: "--long",
+		FetchedAt:   now,
+		ExpiredAt:   now.Add(time.Hour),
+	}).Error)
+
+	gitTags, err := resolver.ResolveFromHashContext(ctx, repo, commitA)
+	r.NoError(err)
+	a.Len(gitTags, 2)
+
+	resolver = newResolver(DescribeOptions{Match: []string{"v*"}})
+	gitTags, err = resolver.ResolveFromHashContext(ctx, repo, commitA)
+	r.NoError(err)
+	r.Len(gitTags, 1)
+	a.Equal("v1.0.0", gitTags[0].Tag)
+
+	resolver = newResolver(DescribeOptions{Long: true})
+	gitTags, err = resolver.ResolveFromHashContext(ctx, repo, commitB)
+	r.NoError(err)
+	r.Len(gitTags, 1)
+	a.Equal("v1.0.0-2-gbbbbbbbbbb", gitTags[0].Tag)
+}
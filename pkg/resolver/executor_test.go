@@ -0,0 +1,122 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	gitdescribe "github.com/thombashi/gh-git-describe/pkg/executor"
+)
+
+// localGitExecutor is a git-describe executor that runs git commands in a local repository
+// instead of a clone of a GitHub repository
+type localGitExecutor struct {
+	dirPath string
+}
+
+func (e localGitExecutor) GetLogger() *slog.Logger {
+	return testLogger
+}
+
+func (e localGitExecutor) RunRepoClone(params *gitdescribe.RepoCloneParams) (string, error) {
+	return e.RunRepoCloneContext(context.Background(), params)
+}
+
+func (e localGitExecutor) RunRepoCloneContext(ctx context.Context, params *gitdescribe.RepoCloneParams) (string, error) {
+	return e.dirPath, nil
+}
+
+func (e localGitExecutor) RunGit(params *gitdescribe.RepoCloneParams, command string, args ...string) (string, error) {
+	return e.RunGitContext(context.Background(), params, command, args...)
+}
+
+func (e localGitExecutor) RunGitContext(ctx context.Context, params *gitdescribe.RepoCloneParams, command string, args ...string) (string, error) {
+	gitArgs := append([]string{"-C", e.dirPath, command}, args...)
+
+	output, err := exec.CommandContext(ctx, "git", gitArgs...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run git %s: %w", strings.Join(gitArgs, " "), err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (e localGitExecutor) RunGitDescribe(params *gitdescribe.RepoCloneParams, args ...string) (string, error) {
+	return e.RunGitContext(context.Background(), params, "describe", args...)
+}
+
+func (e localGitExecutor) RunGitDescribeContext(ctx context.Context, params *gitdescribe.RepoCloneParams, args ...string) (string, error) {
+	return e.RunGitContext(ctx, params, "describe", args...)
+}
+
+func (e localGitExecutor) RunGitRevParse(params *gitdescribe.RepoCloneParams, args ...string) (string, error) {
+	return e.RunGitContext(context.Background(), params, "rev-parse", args...)
+}
+
+func (e localGitExecutor) RunGitRevParseContext(ctx context.Context, params *gitdescribe.RepoCloneParams, args ...string) (string, error) {
+	return e.RunGitContext(ctx, params, "rev-parse", args...)
+}
+
+func (e localGitExecutor) RunGitRevList(params *gitdescribe.RepoCloneParams, args ...string) (string, error) {
+	return e.RunGitContext(context.Background(), params, "rev-list", args...)
+}
+
+func (e localGitExecutor) RunGitRevListContext(ctx context.Context, params *gitdescribe.RepoCloneParams, args ...string) (string, error) {
+	return e.RunGitContext(ctx, params, "rev-list", args...)
+}
+
+// testGitRepo is a local git repository for tests
+type testGitRepo struct {
+	t       *testing.T
+	dirPath string
+	now     time.Time
+}
+
+func newTestGitRepo(t *testing.T) *testGitRepo {
+	t.Helper()
+
+	repo := &testGitRepo{
+		t:       t,
+		dirPath: t.TempDir(),
+		now:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	repo.git("init", "--quiet", "--initial-branch=main")
+
+	return repo
+}
+
+func (g *testGitRepo) git(args ...string) string {
+	g.t.Helper()
+
+	date := g.now.Format(time.RFC3339)
+	cmd := exec.Command("git", append([]string{"-C", g.dirPath}, args...)...)
+	cmd.Env = append(cmd.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE="+date,
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null",
+	)
+
+	output, err := cmd.CombinedOutput()
+	require.NoError(g.t, err, string(output))
+
+	return strings.TrimSpace(string(output))
+}
+
+// commit creates an empty commit and returns the commit hash
+func (g *testGitRepo) commit(message string) string {
+	g.t.Helper()
+
+	g.now = g.now.Add(time.Hour)
+	g.git("commit", "--quiet", "--allow-empty", "-m", message)
+
+	return g.git("rev-parse", "HEAD")
+}
+
+func (g *testGitRepo) executor() gitdescribe.Executor {
+	return localGitExecutor{dirPath: g.dirPath}
+}
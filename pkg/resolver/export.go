@@ -0,0 +1,511 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// CacheExportVersion is the version of the cache export format.
+	// The checksum and the signature of the version 2 cover the header as well as the records.
+	CacheExportVersion = 2
+
+	checksumPrefix  = "sha256:"
+	signaturePrefix = "hmac-sha256:"
+)
+
+// repoIDRegexp matches a GitHub repository ID formatted as "owner/name"
+var repoIDRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// ExportFormat is the file format of a cache export
+type ExportFormat string
+
+const (
+	// ExportFormatJSON is a single JSON document that contains all the records
+	ExportFormatJSON ExportFormat = "json"
+
+	// ExportFormatNDJSON is a newline-delimited JSON that has a header line followed by a line per record
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// CacheRecord is a portable representation of a cached git tag
+type CacheRecord struct {
+	RepoID     string    `json:"repoId"`
+	Tag        string    `json:"tag"`
+	BaseTag    string    `json:"baseTag"`
+	TagHash    string    `json:"tagHash"`
+	CommitHash string    `json:"commitHash"`
+	Source     string    `json:"source,omitempty"`
+	FetchedAt  time.Time `json:"fetchedAt"`
+	ExpiredAt  time.Time `json:"expiredAt"`
+
+	DescribeKey       string     `json:"describeKey,omitempty"`
+	UpstreamDeletedAt *time.Time `json:"upstreamDeletedAt,omitempty"`
+}
+
+func newCacheRecord(gitTag GitTag) CacheRecord {
+	fetchedAt := gitTag.FetchedAt
+	if fetchedAt.IsZero() {
+		// records created by older versions do not have the fetched time
+		fetchedAt = gitTag.UpdatedAt
+	}
+
+	record := CacheRecord{
+		RepoID:     gitTag.RepoID,
+		Tag:        gitTag.Tag,
+		BaseTag:    gitTag.BaseTag,
+		TagHash:    gitTag.TagHash,
+		CommitHash: gitTag.CommitHash,
+		Source:     gitTag.Source,
+		FetchedAt:  fetchedAt.UTC(),
+		ExpiredAt:  gitTag.ExpiredAt.UTC(),
+
+		DescribeKey: gitTag.DescribeKey,
+	}
+	if gitTag.UpstreamDeletedAt != nil {
+		deletedAt := gitTag.UpstreamDeletedAt.UTC()
+		record.UpstreamDeletedAt = &deletedAt
+	}
+
+	return record
+}
+
+// validate validates the repository ID, the tag and the hashes of a record
+func (c CacheRecord) validate() error {
+	owner, name, _ := strings.Cut(c.RepoID, "/")
+	if !repoIDRegexp.MatchString(c.RepoID) || strings.Trim(owner, ".") == "" || strings.Trim(name, ".") == "" {
+		return fmt.Errorf("invalid repository ID: %q", c.RepoID)
+	}
+
+	if strings.TrimSpace(c.Tag) == "" {
+		return fmt.Errorf("%s: require a tag", c.RepoID)
+	}
+
+	if !IsSHA(c.CommitHash) {
+		return fmt.Errorf("%s@%s: invalid commit hash: %q", c.RepoID, c.Tag, c.CommitHash)
+	}
+
+	if !IsSHA(c.TagHash) {
+		return fmt.Errorf("%s@%s: invalid tag hash: %q", c.RepoID, c.Tag, c.TagHash)
+	}
+
+	switch c.Source {
+	case "", SourceGraphQL, SourceGit:
+	default:
+		return fmt.Errorf("%s@%s: invalid source: %q", c.RepoID, c.Tag, c.Source)
+	}
+
+	return nil
+}
+
+func (c CacheRecord) toGitTag() *GitTag {
+	return &GitTag{
+		RepoID:     c.RepoID,
+		Tag:        c.Tag,
+		BaseTag:    c.BaseTag,
+		TagHash:    c.TagHash,
+		CommitHash: c.CommitHash,
+		Source:     c.Source,
+		FetchedAt:  c.FetchedAt,
+		ExpiredAt:  c.ExpiredAt,
+
+		DescribeKey:       c.DescribeKey,
+		UpstreamDeletedAt: c.UpstreamDeletedAt,
+	}
+}
+
+// CacheExport represents the cached records exported from a cache database
+type CacheExport struct {
+	// Version is the version of the export format
+	Version int `json:"version"`
+
+	// Generator is the name of the tool that generated the export
+	Generator string `json:"generator"`
+
+	// ExportedAt is the time when the records are exported
+	ExportedAt time.Time `json:"exportedAt"`
+
+	// Checksum is the SHA-256 checksum of the header and the records
+	Checksum string `json:"checksum"`
+
+	// Signature is the HMAC-SHA256 signature of the header and the records.
+	// Empty if the export is not signed.
+	Signature string `json:"signature,omitempty"`
+
+	// Records is the exported records
+	Records []CacheRecord `json:"records,omitempty"`
+}
+
+// payload returns the canonical byte representation of the header and the records
+// that is used to calculate the checksum and the signature.
+// The header line has the version, the generator and the exported time.
+func (e CacheExport) payload() ([]byte, error) {
+	var buf bytes.Buffer
+
+	header, err := json.Marshal(map[string]any{
+		"version":    e.Version,
+		"generator":  e.Generator,
+		"exportedAt": e.ExportedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the header: %w", err)
+	}
+
+	buf.Write(header)
+	buf.WriteByte('\n')
+
+	for _, record := range e.Records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal a record: %w", err)
+		}
+
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (e *CacheExport) updateChecksum() error {
+	payload, err := e.payload()
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(payload)
+	e.Checksum = checksumPrefix + hex.EncodeToString(sum[:])
+
+	return nil
+}
+
+func calcSignature(payload, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign signs the header and the records with the HMAC-SHA256 key
+func (e *CacheExport) Sign(key []byte) error {
+	if len(key) == 0 {
+		return errors.New("require a signing key")
+	}
+
+	payload, err := e.payload()
+	if err != nil {
+		return err
+	}
+
+	e.Signature = calcSignature(payload, key)
+
+	return nil
+}
+
+// Verify verifies the checksum of the header and the records.
+// If the key is specified, it also verifies the signature.
+func (e CacheExport) Verify(key []byte) error {
+	payload, err := e.payload()
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(payload)
+	if e.Checksum != checksumPrefix+hex.EncodeToString(sum[:]) {
+		return errors.New("checksum mismatch")
+	}
+
+	if len(key) == 0 {
+		return nil
+	}
+
+	if e.Signature == "" {
+		return errors.New("the export is not signed")
+	}
+
+	if !hmac.Equal([]byte(e.Signature), []byte(calcSignature(payload, key))) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+// Write writes the export in the specified format
+func (e CacheExport) Write(w io.Writer, format ExportFormat) error {
+	switch format {
+	case ExportFormatJSON:
+		data, err := json.MarshalIndent(e, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the export: %w", err)
+		}
+
+		if _, err := fmt.Fprintln(w, string(data)); err != nil {
+			return fmt.Errorf("failed to write the export: %w", err)
+		}
+
+	case ExportFormatNDJSON:
+		header := e
+		header.Records = nil
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(header); err != nil {
+			return fmt.Errorf("failed to write the header: %w", err)
+		}
+
+		for _, record := range e.Records {
+			if err := enc.Encode(record); err != nil {
+				return fmt.Errorf("failed to write a record: %w", err)
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	return nil
+}
+
+// ReadCacheExport reads an export written in either of the JSON or the NDJSON format
+func ReadCacheExport(rd io.Reader) (*CacheExport, error) {
+	var export CacheExport
+
+	dec := json.NewDecoder(rd)
+	if err := dec.Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to decode the export: %w", err)
+	}
+
+	if export.Version != CacheExportVersion {
+		return nil, fmt.Errorf("unsupported export version: %d", export.Version)
+	}
+
+	// records follow the header line in the NDJSON format
+	for dec.More() {
+		var record CacheRecord
+		if err := dec.Decode(&record); err != nil {
+			return nil, fmt.Errorf("failed to decode a record: %w", err)
+		}
+
+		export.Records = append(export.Records, record)
+	}
+
+	return &export, nil
+}
+
+// ParseExportFormat parses an export format string
+func ParseExportFormat(format string) (ExportFormat, error) {
+	switch ExportFormat(strings.ToLower(strings.TrimSpace(format))) {
+	case ExportFormatJSON:
+		return ExportFormatJSON, nil
+
+	case ExportFormatNDJSON:
+		return ExportFormatNDJSON, nil
+
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// ExportCache exports the unexpired cached records of the repositories.
+// If no repository is specified, it exports the records of all the repositories.
+func (r Resolver) ExportCache(ctx context.Context, repos []repository.Repository) (*CacheExport, error) {
+	var gitTags []GitTag
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where(whereNotExpired, now)
+
+		if len(repos) > 0 {
+			repoIDs := make([]string, 0, len(repos))
+			for _, repo := range repos {
+				repoIDs = append(repoIDs, ToRepoID(repo))
+			}
+
+			query = query.Where("repo_id IN ?", repoIDs)
+		}
+
+		return query.Order("repo_id").Order("tag").Find(&gitTags).Error
+	}, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to select records: %w", err)
+	}
+
+	export := &CacheExport{
+		Version:    CacheExportVersion,
+		Generator:  extensionName,
+		ExportedAt: now.UTC(),
+		Records:    make([]CacheRecord, 0, len(gitTags)),
+	}
+	for _, gitTag := range gitTags {
+		export.Records = append(export.Records, newCacheRecord(gitTag))
+	}
+
+	if err := export.updateChecksum(); err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug("exported cache records", slog.Int("count", len(export.Records)))
+
+	return export, nil
+}
+
+// ImportResult represents the result of an import
+type ImportResult struct {
+	// Created is the number of the records newly added to the cache
+	Created int `json:"created"`
+
+	// Updated is the number of the records replaced by newer records
+	Updated int `json:"updated"`
+
+	// Skipped is the number of the records that are expired or older than the cached ones
+	Skipped int `json:"skipped"`
+}
+
+// ImportCache merges the exported records into the cache database.
+// If a tag of a repository is already cached, the record fetched later wins.
+// Expired records are skipped. The import fails if any record is invalid.
+// The tags fetched from GitHub are recorded to the tag history, and the moved tags are reported as well as fetching.
+// The export must be verified by CacheExport.Verify before importing.
+func (r *Resolver) ImportCache(ctx context.Context, export *CacheExport) (*ImportResult, error) {
+	for i, record := range export.Records {
+		if err := record.validate(); err != nil {
+			return nil, fmt.Errorf("invalid record #%d: %w", i+1, err)
+		}
+	}
+
+	result := &ImportResult{}
+	now := time.Now()
+	var tagMoves []TagMove
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the earliest expiry of the imported tags fetched by GraphQL per repository
+		tagListExpiredAts := map[string]time.Time{}
+
+		for _, record := range export.Records {
+			if record.ExpiredAt.Before(now) {
+				result.Skipped++
+				continue
+			}
+
+			var existTags []GitTag
+			where := &GitTag{RepoID: record.RepoID, Tag: record.Tag}
+			// the records resolved by 'git describe' are cached per describe options
+			if err := tx.Where(where).Where("describe_key = ?", record.DescribeKey).Find(&existTags).Error; err != nil {
+				return fmt.Errorf("failed to select records: %w", err)
+			}
+
+			if len(existTags) > 0 {
+				isNewer := true
+				for _, existTag := range existTags {
+					if !record.FetchedAt.After(newCacheRecord(existTag).FetchedAt) {
+						isNewer = false
+						break
+					}
+				}
+
+				if !isNewer {
+					result.Skipped++
+					continue
+				}
+
+				if err := tx.Unscoped().Where(where).Where("describe_key = ?", record.DescribeKey).Delete(&GitTag{}).Error; err != nil {
+					return fmt.Errorf("failed to delete records: %w", err)
+				}
+			}
+
+			// the imported records are not a complete tag list, so the deleted tags are not reconciled
+			if record.Source == SourceGraphQL && record.UpstreamDeletedAt == nil {
+				moves, err := importTagHistory(tx, record)
+				if err != nil {
+					return err
+				}
+
+				tagMoves = append(tagMoves, moves...)
+			}
+
+			if record.Source == SourceGraphQL {
+				if expiredAt, ok := tagListExpiredAts[record.RepoID]; !ok || record.ExpiredAt.Before(expiredAt) {
+					tagListExpiredAts[record.RepoID] = record.ExpiredAt
+				}
+			}
+
+			if err := tx.Create(record.toGitTag()).Error; err != nil {
+				return fmt.Errorf("failed to create a record: %w", err)
+			}
+
+			if len(existTags) > 0 {
+				result.Updated++
+			} else {
+				result.Created++
+			}
+		}
+
+		// the imported tag lists are used without fetching until the earliest of the tags expires
+		for repoID, tagListExpiredAt := range tagListExpiredAts {
+			result := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "repo_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"tag_list_expired_at": tagListExpiredAt,
+					"updated_at":          now,
+				}),
+			}).Create(&GitRepo{
+				RepoID:           repoID,
+				LastAccessedAt:   now,
+				TagListExpiredAt: tagListExpiredAt,
+			})
+			if result.Error != nil {
+				return fmt.Errorf("failed to update the tag list expiry of %s: %w", repoID, result.Error)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import the cache: %w", err)
+	}
+
+	r.reportTagMoves(tagMoves)
+
+	r.logger.Debug("imported cache records",
+		slog.Int("created", result.Created),
+		slog.Int("updated", result.Updated),
+		slog.Int("skipped", result.Skipped),
+	)
+
+	return result, nil
+}
+
+// importTagHistory records the tag of an imported record to the history table.
+// The record fetched before the latest observation of the tag is not recorded,
+// since it does not tell the current object of the tag.
+func importTagHistory(tx *gorm.DB, record CacheRecord) ([]TagMove, error) {
+	var histories []GitTagHistory
+
+	result := tx.Where(&GitTagHistory{RepoID: record.RepoID, Tag: record.Tag}).Where("last_seen_at > ?", record.FetchedAt).Limit(1).Find(&histories)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to select tag histories: %w", result.Error)
+	}
+
+	if len(histories) > 0 {
+		return nil, nil
+	}
+
+	hash := Hash{CommitHash: record.CommitHash, TagHash: record.TagHash}
+
+	return recordTagHistory(tx, record.RepoID, map[string]Hash{record.Tag: hash}, record.FetchedAt)
+}
@@ -0,0 +1,282 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheExport_WriteAndRead(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	h1 := strings.Repeat("1", 40)
+	h2 := strings.Repeat("2", 40)
+	export := &CacheExport{
+		Version:    CacheExportVersion,
+		Generator:  extensionName,
+		ExportedAt: now,
+		Records: []CacheRecord{
+			{RepoID: "owner/a", Tag: "v1.0.0", BaseTag: "v1.0.0", TagHash: h1, CommitHash: h1, FetchedAt: now, ExpiredAt: now.Add(time.Hour)},
+			{RepoID: "owner/a", Tag: "v2.0.0", BaseTag: "v2.0.0", TagHash: h2, CommitHash: h2, FetchedAt: now, ExpiredAt: now.Add(time.Hour)},
+		},
+	}
+	require.NoError(t, export.updateChecksum())
+	require.NoError(t, export.Sign([]byte("secret")))
+
+	for _, format := range []ExportFormat{ExportFormatJSON, ExportFormatNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			a := assert.New(t)
+			r := require.New(t)
+
+			var buf bytes.Buffer
+			r.NoError(export.Write(&buf, format))
+
+			got, err := ReadCacheExport(&buf)
+			r.NoError(err)
+			a.Equal(export.Checksum, got.Checksum)
+			a.Equal(export.Records, got.Records)
+			a.NoError(got.Verify(nil))
+			a.NoError(got.Verify([]byte("secret")))
+			a.Error(got.Verify([]byte("wrong")))
+
+			got.Records[0].CommitHash = h2
+			a.Error(got.Verify(nil))
+
+			// the header is covered by the checksum and the signature as well
+			got.Records[0].CommitHash = h1
+			got.ExportedAt = now.Add(time.Hour)
+			a.Error(got.Verify(nil))
+			a.Error(got.Verify([]byte("secret")))
+		})
+	}
+}
+
+func TestReadCacheExport_UnsupportedVersion(t *testing.T) {
+	for _, version := range []int{1, 999} {
+		_, err := ReadCacheExport(bytes.NewBufferString(fmt.Sprintf(`{"version": %d}`, version)))
+		assert.Error(t, err, version)
+	}
+}
+
+func TestResolver_ImportCache(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	var tagMoves []TagMove
+	resolver := newTestResolver(t, &Params{
+		OnTagMove: func(move TagMove) {
+			tagMoves = append(tagMoves, move)
+		},
+	})
+	now := time.Now().UTC()
+	expiredAt := now.Add(time.Hour)
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	hashOf := func(c string) Hash {
+		return Hash{CommitHash: strings.Repeat(c, 40), TagHash: strings.Repeat(c, 40)}
+	}
+	oldHash, cachedHash, newHash, staleHash, addedHash, expiredHash := hashOf("1"), hashOf("2"), hashOf("3"), hashOf("4"), hashOf("5"), hashOf("6")
+
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1.0.0": oldHash, "v2.0.0": cachedHash}, now))
+
+	// the cached record and the last observation of v1.0.0 are older than the exported one
+	r.NoError(resolver.db.Model(&GitTag{}).Where(&GitTag{RepoID: "owner/a", Tag: "v1.0.0"}).
+		Update("fetched_at", now.Add(-time.Hour)).Error)
+	r.NoError(resolver.db.Model(&GitTagHistory{}).Where(&GitTagHistory{RepoID: "owner/a", Tag: "v1.0.0"}).
+		Update("last_seen_at", now.Add(-time.Hour)).Error)
+
+	newRecord := func(tag string, hash Hash, fetchedAt, expiredAt time.Time) CacheRecord {
+		return CacheRecord{
+			RepoID:     "owner/a",
+			Tag:        tag,
+			BaseTag:    tag,
+			CommitHash: hash.CommitHash,
+			TagHash:    hash.TagHash,
+			Source:     SourceGraphQL,
+			FetchedAt:  fetchedAt,
+			ExpiredAt:  expiredAt,
+		}
+	}
+
+	export := &CacheExport{
+		Version: CacheExportVersion,
+		Records: []CacheRecord{
+			newRecord("v1.0.0", newHash, now.Add(-time.Minute), expiredAt),
+			newRecord("v2.0.0", staleHash, now.Add(-time.Minute), expiredAt),
+			newRecord("v3.0.0", addedHash, now, expiredAt),
+			newRecord("v4.0.0", expiredHash, now, now.Add(-time.Minute)),
+		},
+	}
+
+	result, err := resolver.ImportCache(ctx, export)
+	r.NoError(err)
+	a.Equal(&ImportResult{Created: 1, Updated: 1, Skipped: 2}, result)
+
+	gitTags, err := resolver.ListCache(ctx, repo)
+	r.NoError(err)
+
+	got := map[string]string{}
+	for _, gitTag := range gitTags {
+		got[gitTag.Tag] = gitTag.CommitHash
+	}
+	a.Equal(map[string]string{
+		"v1.0.0": newHash.CommitHash,
+		"v2.0.0": cachedHash.CommitHash,
+		"v3.0.0": addedHash.CommitHash,
+	}, got)
+
+	// the imported tags are recorded to the history, and the moved tag is reported
+	r.Len(tagMoves, 1)
+	a.Equal("v1.0.0", tagMoves[0].Tag)
+	a.Equal(oldHash, tagMoves[0].From)
+	a.Equal(newHash, tagMoves[0].To)
+
+	histories, err := resolver.TagHistory(ctx, repo, "v3.0.0")
+	r.NoError(err)
+	r.Len(histories, 1)
+	a.Equal(addedHash, histories[0].hash())
+
+	exported, err := resolver.ExportCache(ctx, []repository.Repository{repo})
+	r.NoError(err)
+	a.Len(exported.Records, 3)
+	a.NoError(exported.Verify(nil))
+}
+
+func TestResolver_ImportCache_RoundTrip(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+	h1 := Hash{CommitHash: strings.Repeat("1", 40), TagHash: strings.Repeat("a", 40)}
+	h2 := Hash{CommitHash: strings.Repeat("2", 40), TagHash: strings.Repeat("b", 40)}
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	source := newTestResolver(t, &Params{})
+	r.NoError(source.storeTags(ctx, repo, map[string]Hash{"v1.0.0": h1, "v1.1.0": h2, "v1": h2}, time.Now()))
+
+	export, err := source.ExportCache(ctx, []repository.Repository{repo})
+	r.NoError(err)
+
+	// every query fails since the fake serves no repository
+	fake := newFakeGraphQL()
+	resolver := newTestResolver(t, &Params{Client: fake.client(t)})
+
+	result, err := resolver.ImportCache(ctx, export)
+	r.NoError(err)
+	a.Equal(3, result.Created)
+
+	resolvedRef, err := resolver.ResolveRefContext(ctx, repo, "v1.1.0")
+	r.NoError(err)
+	a.Equal(h2.CommitHash, resolvedRef.CommitHash)
+
+	gitTag, err := resolver.ResolveVersionQuery(ctx, repo, "latest", nil)
+	r.NoError(err)
+	a.Equal("v1.1.0", gitTag.Tag)
+	a.Zero(fake.queries("owner/a"))
+}
+
+func TestResolver_ImportCache_OlderThanHistory(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	oldHash := Hash{CommitHash: strings.Repeat("1", 40), TagHash: strings.Repeat("1", 40)}
+	newHash := Hash{CommitHash: strings.Repeat("2", 40), TagHash: strings.Repeat("2", 40)}
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	var tagMoves []TagMove
+	resolver := newTestResolver(t, &Params{
+		OnTagMove: func(move TagMove) {
+			tagMoves = append(tagMoves, move)
+		},
+	})
+
+	// the tag was observed at the new hash, and the cached record is pruned afterward
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1.0.0": newHash}, now))
+	r.NoError(resolver.db.Unscoped().Where(&GitTag{RepoID: "owner/a"}).Delete(&GitTag{}).Error)
+
+	export := &CacheExport{
+		Version: CacheExportVersion,
+		Records: []CacheRecord{
+			{
+				RepoID:     "owner/a",
+				Tag:        "v1.0.0",
+				BaseTag:    "v1.0.0",
+				CommitHash: oldHash.CommitHash,
+				TagHash:    oldHash.TagHash,
+				Source:     SourceGraphQL,
+				FetchedAt:  now.Add(-time.Hour),
+				ExpiredAt:  now.Add(time.Hour),
+			},
+		},
+	}
+
+	result, err := resolver.ImportCache(ctx, export)
+	r.NoError(err)
+	a.Equal(1, result.Created)
+
+	// the record fetched before the latest observation does not move the tag back
+	a.Empty(tagMoves)
+
+	histories, err := resolver.TagHistory(ctx, repo, "v1.0.0")
+	r.NoError(err)
+	r.Len(histories, 1)
+	a.Equal(newHash, histories[0].hash())
+}
+
+func TestResolver_ImportCache_InvalidRecord(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	hash := strings.Repeat("a", 40)
+
+	valid := CacheRecord{
+		RepoID:     "owner/a",
+		Tag:        "v1.0.0",
+		CommitHash: hash,
+		TagHash:    hash,
+		Source:     SourceGraphQL,
+		FetchedAt:  now,
+		ExpiredAt:  now.Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name   string
+		modify func(record *CacheRecord)
+	}{
+		{"repo ID without name", func(record *CacheRecord) { record.RepoID = "owner" }},
+		{"repo ID with path", func(record *CacheRecord) { record.RepoID = "owner/a/../b" }},
+		{"repo ID with dot name", func(record *CacheRecord) { record.RepoID = "owner/.." }},
+		{"empty tag", func(record *CacheRecord) { record.Tag = "" }},
+		{"short commit hash", func(record *CacheRecord) { record.CommitHash = "aaaa" }},
+		{"invalid tag hash", func(record *CacheRecord) { record.TagHash = "not a hash" }},
+		{"unknown source", func(record *CacheRecord) { record.Source = "unknown" }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := assert.New(t)
+			r := require.New(t)
+
+			resolver := newTestResolver(t, nil)
+
+			invalid := valid
+			tc.modify(&invalid)
+
+			_, err := resolver.ImportCache(ctx, &CacheExport{
+				Version: CacheExportVersion,
+				Records: []CacheRecord{valid, invalid},
+			})
+			a.Error(err)
+
+			var count int64
+			r.NoError(resolver.db.Model(&GitTag{}).Count(&count).Error)
+			a.Zero(count, "no record is imported")
+		})
+	}
+}
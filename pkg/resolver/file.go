@@ -0,0 +1,51 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	gitdescribe "github.com/thombashi/gh-git-describe/pkg/executor"
+)
+
+// ReadFileContext reads a file of a repository at a revision (e.g. a commit hash or a tag).
+// It uses the git clone cache of the repository, and fetches a commit hash missing in the clone.
+func (r Resolver) ReadFileContext(ctx context.Context, repo repository.Repository, rev, path string) ([]byte, error) {
+	repoID := ToRepoID(repo)
+
+	if err := validateRevision(rev); err != nil {
+		return nil, err
+	}
+
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return nil, fmt.Errorf("require a file path")
+	}
+
+	r.logger.Debug("reading a file", slog.String("repo", repoID), slog.String("rev", rev), slog.String("path", path))
+
+	cloneParams := &gitdescribe.RepoCloneParams{
+		RepoID:   repoID,
+		CacheTTL: r.cacheTTL.GitFileTTL,
+	}
+
+	// a commit pushed after the repository is cloned is fetched into the clone
+	if IsSHA(rev) {
+		if _, err := r.gdExecutor.RunGitContext(ctx, cloneParams, "cat-file", "-e", rev+"^{commit}"); err != nil {
+			r.logger.Debug("fetching a commit missing in the clone", slog.String("repo", repoID), slog.String("rev", rev))
+
+			if _, err := r.gdExecutor.RunGitContext(ctx, cloneParams, "fetch", "--quiet", "origin", rev); err != nil {
+				return nil, fmt.Errorf("failed to fetch the commit: %s@%s: %w", repoID, rev, err)
+			}
+		}
+	}
+
+	content, err := r.gdExecutor.RunGitContext(ctx, cloneParams, "cat-file", "blob", rev+":"+path)
+	if err != nil {
+		return nil, fmt.Errorf("the file is not found in the repository: %s@%s:%s: %w", repoID, rev, path, err)
+	}
+
+	return []byte(content), nil
+}
@@ -0,0 +1,70 @@
+package resolver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_ReadFileContext(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	gitRepo := newTestGitRepo(t)
+	r.NoError(os.WriteFile(filepath.Join(gitRepo.dirPath, "action.yml"), []byte("runs:\n  using: composite\n"), 0o600))
+	gitRepo.git("add", "action.yml")
+	first := gitRepo.commit("add action.yml")
+
+	r.NoError(os.WriteFile(filepath.Join(gitRepo.dirPath, "action.yml"), []byte("runs:\n  using: node20\n"), 0o600))
+	gitRepo.git("add", "action.yml")
+	gitRepo.commit("update action.yml")
+
+	resolver := newTestResolver(t, &Params{GitDescExecutor: gitRepo.executor()})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	content, err := resolver.ReadFileContext(ctx, repo, first, "action.yml")
+	r.NoError(err)
+	a.Equal("runs:\n  using: composite", string(content))
+
+	content, err = resolver.ReadFileContext(ctx, repo, "HEAD", "/action.yml")
+	r.NoError(err)
+	a.Equal("runs:\n  using: node20", string(content))
+
+	_, err = resolver.ReadFileContext(ctx, repo, first, "action.yaml")
+	a.Error(err)
+
+	_, err = resolver.ReadFileContext(ctx, repo, "--all", "action.yml")
+	a.Error(err)
+}
+
+func TestResolver_ReadFileContext_MissingCommit(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	upstream := newTestGitRepo(t)
+	r.NoError(os.WriteFile(filepath.Join(upstream.dirPath, "action.yml"), []byte("runs:\n  using: composite\n"), 0o600))
+	upstream.git("add", "action.yml")
+	upstream.commit("add action.yml")
+
+	// the cached clone does not have the commits pushed after it is cloned
+	cloneDirPath := filepath.Join(t.TempDir(), "clone")
+	upstream.git("clone", "--quiet", "--bare", upstream.dirPath, cloneDirPath)
+
+	r.NoError(os.WriteFile(filepath.Join(upstream.dirPath, "action.yml"), []byte("runs:\n  using: node20\n"), 0o600))
+	upstream.git("add", "action.yml")
+	second := upstream.commit("update action.yml")
+
+	resolver := newTestResolver(t, &Params{GitDescExecutor: localGitExecutor{dirPath: cloneDirPath}})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	content, err := resolver.ReadFileContext(ctx, repo, second, "action.yml")
+	r.NoError(err)
+	a.Equal("runs:\n  using: node20", string(content))
+}
@@ -0,0 +1,152 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	gitdescribe "github.com/thombashi/gh-git-describe/pkg/executor"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	githubModulePrefix = "github.com/"
+	incompatibleSuffix = "+incompatible"
+)
+
+// GoModuleVersion represents a version of a Go module resolved to a tag and a commit
+type GoModuleVersion struct {
+	// Path is the module path (e.g. "github.com/owner/repo/sub/v2")
+	Path string
+
+	// Version is the module version (e.g. "v2.1.0", "v0.0.0-20240101120000-abcdef123456")
+	Version string
+
+	// RepoID is the repository that hosts the module
+	RepoID string
+
+	// Tag is the tag of the version (e.g. "sub/v2.1.0"). Empty if the version is a pseudo-version.
+	Tag string
+
+	// Pseudo is true if the version is a pseudo-version
+	Pseudo bool
+
+	// CommitHash is the full commit hash of the version
+	CommitHash string
+
+	// NearestTag is the nearest tag of the module that the commit is reachable from.
+	// The same as Tag if the version is not a pseudo-version.
+	// Empty if the commit is not reachable from any tag of the module.
+	NearestTag string
+}
+
+// String returns the module version formatted as "path@version"
+func (v GoModuleVersion) String() string {
+	return v.Path + "@" + v.Version
+}
+
+// ParseGoModulePath parses the path of a Go module hosted on GitHub.
+// It returns the repository and the tag prefix of the module: the directory of the module in the repository
+// including the trailing "/" (e.g. "sub/" of "github.com/owner/repo/sub/v2"), or empty for the root module.
+func ParseGoModulePath(modulePath string) (repository.Repository, string, error) {
+	if !strings.HasPrefix(modulePath, githubModulePrefix) {
+		return repository.Repository{}, "", fmt.Errorf("not a module hosted on GitHub: %s", modulePath)
+	}
+
+	pathPrefix, _, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		return repository.Repository{}, "", fmt.Errorf("invalid module path: %s", modulePath)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(pathPrefix, githubModulePrefix), "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return repository.Repository{}, "", fmt.Errorf("invalid module path: %s", modulePath)
+	}
+
+	repo := repository.Repository{Host: "github.com", Owner: parts[0], Name: parts[1]}
+	if len(parts) < 3 {
+		return repo, "", nil
+	}
+
+	return repo, parts[2] + "/", nil
+}
+
+// nearestModuleTagFromGitObj returns the nearest tag of a module that a commit is reachable from.
+// It returns an empty string if no tag is found.
+func (r Resolver) nearestModuleTagFromGitObj(ctx context.Context, repoID, prefix, commitHash string) string {
+	tag, err := r.gdExecutor.RunGitDescribeContext(ctx, &gitdescribe.RepoCloneParams{
+		RepoID:   repoID,
+		CacheTTL: r.cacheTTL.GitFileTTL,
+	}, "--tags", "--abbrev=0", "--match", prefix+"v[0-9]*", commitHash)
+	if err != nil {
+		r.logger.Debug("no tag of the module is found",
+			slog.String("repo", repoID),
+			slog.String("prefix", prefix),
+			slog.String("commit", commitHash),
+			slog.String("error", err.Error()))
+		return ""
+	}
+
+	return tag
+}
+
+// ResolveGoModuleContext resolves a version of a Go module hosted on GitHub to the tag and the full commit hash.
+// A pseudo-version is resolved from the commit hash prefix embedded in the version with the git clone cache,
+// and the nearest tag of the module is resolved by 'git describe'.
+// The tags of the modules in the subdirectories are prefixed with the directories (e.g. "sub/v1.2.3"),
+// and the "+incompatible" suffix is dropped to find the tag.
+func (r *Resolver) ResolveGoModuleContext(ctx context.Context, modulePath, version string) (*GoModuleVersion, error) {
+	if !semver.IsValid(version) {
+		return nil, fmt.Errorf("invalid module version: %s", version)
+	}
+
+	repo, prefix, err := ParseGoModulePath(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	repoID := ToRepoID(repo)
+	result := &GoModuleVersion{
+		Path:    modulePath,
+		Version: version,
+		RepoID:  repoID,
+		Pseudo:  module.IsPseudoVersion(version),
+	}
+
+	r.logger.Debug("resolving a module version",
+		slog.String("repo", repoID),
+		slog.String("module", modulePath),
+		slog.String("version", version))
+
+	if result.Pseudo {
+		rev, err := module.PseudoVersionRev(version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pseudo-version: %s: %w", version, err)
+		}
+
+		commitHash, err := r.resolveCommitFromGitObj(ctx, repoID, rev)
+		if err != nil {
+			return nil, err
+		}
+
+		result.CommitHash = commitHash
+		result.NearestTag = r.nearestModuleTagFromGitObj(ctx, repoID, prefix, commitHash)
+
+		return result, nil
+	}
+
+	result.Tag = prefix + strings.TrimSuffix(version, incompatibleSuffix)
+
+	gitTag, err := r.ResolveFromTagContext(ctx, repo, result.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	result.CommitHash = gitTag.CommitHash
+	result.NearestTag = result.Tag
+
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoModulePath(t *testing.T) {
+	a := assert.New(t)
+
+	testCases := []struct {
+		path       string
+		wantRepo   string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{path: "github.com/owner/repo", wantRepo: "owner/repo"},
+		{path: "github.com/owner/repo/v2", wantRepo: "owner/repo"},
+		{path: "github.com/owner/repo/sub", wantRepo: "owner/repo", wantPrefix: "sub/"},
+		{path: "github.com/owner/repo/sub/dir/v3", wantRepo: "owner/repo", wantPrefix: "sub/dir/"},
+		{path: "golang.org/x/mod", wantErr: true},
+		{path: "github.com/owner", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		repo, prefix, err := ParseGoModulePath(tc.path)
+		if tc.wantErr {
+			a.Error(err, tc.path)
+			continue
+		}
+
+		a.NoError(err, tc.path)
+		a.Equal(tc.wantRepo, ToRepoID(repo), tc.path)
+		a.Equal(tc.wantPrefix, prefix, tc.path)
+	}
+}
+
+func TestResolver_ResolveGoModuleContext(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	gitRepo := newTestGitRepo(t)
+	v100 := gitRepo.commit("initial")
+	gitRepo.git("tag", "v1.0.0")
+	sub120 := gitRepo.commit("sub")
+	gitRepo.git("tag", "sub/v1.2.0")
+	v200 := gitRepo.commit("v2")
+	gitRepo.git("tag", "v2.0.0")
+	untagged := gitRepo.commit("untagged")
+
+	resolver := newTestResolver(t, &Params{GitDescExecutor: gitRepo.executor()})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{
+		"v1.0.0":     {CommitHash: v100, TagHash: v100},
+		"sub/v1.2.0": {CommitHash: sub120, TagHash: sub120},
+		"v2.0.0":     {CommitHash: v200, TagHash: v200},
+	}, time.Now()))
+
+	result, err := resolver.ResolveGoModuleContext(ctx, "github.com/owner/a", "v1.0.0")
+	r.NoError(err)
+	a.False(result.Pseudo)
+	a.Equal("v1.0.0", result.Tag)
+	a.Equal(v100, result.CommitHash)
+
+	result, err = resolver.ResolveGoModuleContext(ctx, "github.com/owner/a", "v2.0.0+incompatible")
+	r.NoError(err)
+	a.Equal("v2.0.0", result.Tag)
+	a.Equal(v200, result.CommitHash)
+
+	result, err = resolver.ResolveGoModuleContext(ctx, "github.com/owner/a/sub", "v1.2.0")
+	r.NoError(err)
+	a.Equal("sub/v1.2.0", result.Tag)
+	a.Equal(sub120, result.CommitHash)
+
+	result, err = resolver.ResolveGoModuleContext(ctx, "github.com/owner/a", "v2.0.1-0.20240101120000-"+untagged[:12])
+	r.NoError(err)
+	a.True(result.Pseudo)
+	a.Empty(result.Tag)
+	a.Equal(untagged, result.CommitHash)
+	a.Equal("v2.0.0", result.NearestTag)
+
+	result, err = resolver.ResolveGoModuleContext(ctx, "github.com/owner/a/sub", "v1.2.1-0.20240101120000-"+untagged[:12])
+	r.NoError(err)
+	a.Equal("sub/v1.2.0", result.NearestTag)
+
+	_, err = resolver.ResolveGoModuleContext(ctx, "github.com/owner/a", "latest")
+	a.Error(err)
+}
@@ -0,0 +1,130 @@
+package resolver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGraphQL is an http.RoundTripper that serves the GraphQL queries of the tags from maps
+// instead of GitHub
+type fakeGraphQL struct {
+	mu sync.Mutex
+
+	// repos is the tags and the hashes per repository ID
+	repos map[string]map[string]Hash
+
+	// tagQueries is the number of the tag queries per repository ID
+	tagQueries map[string]int
+}
+
+func newFakeGraphQL() *fakeGraphQL {
+	return &fakeGraphQL{
+		repos:      map[string]map[string]Hash{},
+		tagQueries: map[string]int{},
+	}
+}
+
+// setTags sets the tags of a repository served by the fake
+func (f *fakeGraphQL) setTags(repoID string, taghashMap map[string]Hash) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.repos[repoID] = taghashMap
+}
+
+// queries returns the number of the tag queries of a repository
+func (f *fakeGraphQL) queries(repoID string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.tagQueries[repoID]
+}
+
+// client returns a GraphQL client that sends the requests to the fake
+func (f *fakeGraphQL) client(t *testing.T) *api.GraphQLClient {
+	t.Helper()
+
+	client, err := api.NewGraphQLClient(api.ClientOptions{
+		AuthToken: "dummy",
+		Host:      "github.com",
+		Transport: f,
+	})
+	require.NoError(t, err)
+
+	return client
+}
+
+func (f *fakeGraphQL) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	if !strings.Contains(body.Query, `refs(refPrefix:"refs/tags/"`) {
+		return jsonResponse(map[string]any{
+			"errors": []map[string]any{{"message": "unsupported query by the fake"}},
+		})
+	}
+
+	repoID := fmt.Sprintf("%v/%v", body.Variables["owner"], body.Variables["name"])
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.tagQueries[repoID]++
+
+	taghashMap, ok := f.repos[repoID]
+	if !ok {
+		return jsonResponse(map[string]any{
+			"data":   map[string]any{"repository": nil},
+			"errors": []map[string]any{{"type": "NOT_FOUND", "message": "Could not resolve to a Repository: " + repoID}},
+		})
+	}
+
+	nodes := []map[string]any{}
+	for tag, hash := range taghashMap {
+		nodes = append(nodes, map[string]any{
+			"name": tag,
+			"target": map[string]any{
+				"oid":                hash.TagHash,
+				"commitResourcePath": "/" + repoID + "/commit/" + hash.CommitHash,
+			},
+		})
+	}
+
+	return jsonResponse(map[string]any{
+		"data": map[string]any{
+			"repository": map[string]any{
+				"refs": map[string]any{
+					"nodes":    nodes,
+					"pageInfo": map[string]any{"hasNextPage": false, "endCursor": ""},
+				},
+			},
+		},
+	})
+}
+
+func jsonResponse(v any) (*http.Response, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+	}, nil
+}
@@ -0,0 +1,168 @@
+package resolver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"gorm.io/gorm"
+)
+
+// TagMove represents a tag observed pointing to a different object from the last observation
+type TagMove struct {
+	// RepoID is the GitHub repository ID formatted as "owner/name"
+	RepoID string
+
+	// Tag is the git tag name
+	Tag string
+
+	// From is the hashes that the tag pointed to at the last observation
+	From Hash
+
+	// To is the hashes that the tag points to now
+	To Hash
+
+	// DetectedAt is the time when the move is detected
+	DetectedAt time.Time
+}
+
+// latestTagHistories returns the latest history record per tag of a repository
+func latestTagHistories(tx *gorm.DB, repoID string) (map[string]GitTagHistory, error) {
+	var histories []GitTagHistory
+
+	result := tx.Where(&GitTagHistory{RepoID: repoID}).Order("last_seen_at").Order("id").Find(&histories)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to select tag histories: %w", result.Error)
+	}
+
+	latest := make(map[string]GitTagHistory, len(histories))
+	for _, history := range histories {
+		latest[history.Tag] = history
+	}
+
+	return latest, nil
+}
+
+// recordTagHistory records the observed tags to the history table.
+// It returns the tags that point to different objects from the last observation.
+func recordTagHistory(tx *gorm.DB, repoID string, taghashMap map[string]Hash, now time.Time) ([]TagMove, error) {
+	latest, err := latestTagHistories(tx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tagMoves []TagMove
+
+	for tag, hash := range taghashMap {
+		history, exist := latest[tag]
+		if exist && history.hash() == hash && history.UpstreamDeletedAt == nil {
+			result := tx.Model(&GitTagHistory{}).Where("id = ?", history.ID).Update("last_seen_at", now)
+			if result.Error != nil {
+				return nil, fmt.Errorf("failed to update a tag history: %w", result.Error)
+			}
+
+			continue
+		}
+
+		// a tag re-created at the same object after deleted upstream is recorded as a new history without a move
+		if exist && history.hash() != hash {
+			tagMoves = append(tagMoves, TagMove{
+				RepoID:     repoID,
+				Tag:        tag,
+				From:       history.hash(),
+				To:         hash,
+				DetectedAt: now,
+			})
+		}
+
+		result := tx.Create(&GitTagHistory{
+			RepoID:      repoID,
+			Tag:         tag,
+			CommitHash:  hash.CommitHash,
+			TagHash:     hash.TagHash,
+			FirstSeenAt: now,
+			LastSeenAt:  now,
+		})
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to create a tag history: %w", result.Error)
+		}
+	}
+
+	return tagMoves, nil
+}
+
+// markDeletedTags marks the cached tags of a repository that are not found in the fetched tags as deleted upstream.
+// The latest history records of the tags are marked as well.
+// The tags found again are unmarked.
+// It returns the tags newly marked as deleted.
+func markDeletedTags(tx *gorm.DB, repoID string, tags []string, now time.Time) ([]string, error) {
+	var deletedTags []string
+
+	// the records resolved from the git objects are excluded because they are not necessarily tags
+	query := tx.Model(&GitTag{}).Where(&GitTag{RepoID: repoID, Source: SourceGraphQL}).Where(whereUpstreamExist)
+	if len(tags) > 0 {
+		query = query.Where("tag NOT IN ?", tags)
+	}
+	if err := query.Distinct().Pluck("tag", &deletedTags).Error; err != nil {
+		return nil, fmt.Errorf("failed to select deleted tags: %w", err)
+	}
+
+	if len(deletedTags) > 0 {
+		result := tx.Model(&GitTag{}).
+			Where(&GitTag{RepoID: repoID}).
+			Where(whereUpstreamExist).
+			Where("tag IN ?", deletedTags).
+			Update(columnUpstreamDeletedAt, now)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to mark deleted tags: %w", result.Error)
+		}
+
+		latest, err := latestTagHistories(tx, repoID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range deletedTags {
+			history, exist := latest[tag]
+			if !exist || history.UpstreamDeletedAt != nil {
+				continue
+			}
+
+			result := tx.Model(&GitTagHistory{}).Where("id = ?", history.ID).Update(columnUpstreamDeletedAt, now)
+			if result.Error != nil {
+				return nil, fmt.Errorf("failed to mark a deleted tag history: %w", result.Error)
+			}
+		}
+	}
+
+	if len(tags) > 0 {
+		result := tx.Model(&GitTag{}).
+			Where(&GitTag{RepoID: repoID}).
+			Where(whereUpstreamDeleted).
+			Where("tag IN ?", tags).
+			Update(columnUpstreamDeletedAt, nil)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to unmark re-created tags: %w", result.Error)
+		}
+	}
+
+	return deletedTags, nil
+}
+
+// TagHistory returns the observed history of a tag in chronological order.
+// If the tag is empty, it returns the history of all the tags of the repository.
+func (r Resolver) TagHistory(ctx context.Context, repo repository.Repository, tag string) ([]GitTagHistory, error) {
+	var histories []GitTagHistory
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		where := &GitTagHistory{RepoID: ToRepoID(repo), Tag: tag}
+		return tx.Where(where).Order("tag").Order("first_seen_at").Order("id").Find(&histories).Error
+	}, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tag histories: %w", err)
+	}
+
+	return histories, nil
+}
@@ -0,0 +1,131 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_TagHistory(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	var tagMoves []TagMove
+	resolver := newTestResolver(t, &Params{
+		OnTagMove: func(move TagMove) {
+			tagMoves = append(tagMoves, move)
+		},
+	})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+	hashA := Hash{CommitHash: "aaaa", TagHash: "aaaa"}
+	hashB := Hash{CommitHash: "bbbb", TagHash: "bbbb"}
+	now := time.Now()
+
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1": hashA, "v1.0.0": hashA}, now))
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1": hashA, "v1.0.0": hashA}, now.Add(time.Minute)))
+	a.Empty(tagMoves)
+
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1": hashB, "v1.0.0": hashA}, now.Add(2*time.Minute)))
+	r.Len(tagMoves, 1)
+	a.Equal("v1", tagMoves[0].Tag)
+	a.Equal(hashA, tagMoves[0].From)
+	a.Equal(hashB, tagMoves[0].To)
+
+	// moving back to the previous object is also a move
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1": hashA, "v1.0.0": hashA}, now.Add(3*time.Minute)))
+	r.Len(tagMoves, 2)
+	a.Equal(hashB, tagMoves[1].From)
+	a.Equal(hashA, tagMoves[1].To)
+
+	histories, err := resolver.TagHistory(ctx, repo, "v1")
+	r.NoError(err)
+	r.Len(histories, 3)
+	a.Equal(hashA, histories[0].hash())
+	a.True(now.Equal(histories[0].FirstSeenAt))
+	a.True(now.Add(time.Minute).Equal(histories[0].LastSeenAt))
+	a.Equal(hashB, histories[1].hash())
+	a.Equal(hashA, histories[2].hash())
+
+	gitTag, err := resolver.ResolveFromTagContext(ctx, repo, "v1")
+	r.NoError(err)
+	a.Equal(hashA.CommitHash, gitTag.CommitHash)
+
+	histories, err = resolver.TagHistory(ctx, repo, "")
+	r.NoError(err)
+	a.Len(histories, 4)
+}
+
+func TestResolver_storeTags_DeletedUpstream(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	var tagMoves []TagMove
+	resolver := newTestResolver(t, &Params{
+		OnTagMove: func(move TagMove) {
+			tagMoves = append(tagMoves, move)
+		},
+	})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+	hash := Hash{CommitHash: "aaaa", TagHash: "aaaa"}
+	now := time.Now()
+
+	findTag := func(tag string) GitTag {
+		gitTags, err := resolver.ListCache(ctx, repo)
+		r.NoError(err)
+
+		for _, gitTag := range gitTags {
+			if gitTag.Tag == tag {
+				return gitTag
+			}
+		}
+
+		r.FailNow("tag not found", tag)
+		return GitTag{}
+	}
+
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1.0.0": hash, "v1.0.1": hash}, now))
+	a.False(findTag("v1.0.1").IsDeletedUpstream())
+
+	// v1.0.1 is deleted upstream
+	deletedAt := now.Add(time.Minute)
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1.0.0": hash}, deletedAt))
+	a.False(findTag("v1.0.0").IsDeletedUpstream())
+
+	gitTag := findTag("v1.0.1")
+	r.True(gitTag.IsDeletedUpstream())
+	a.True(deletedAt.Equal(*gitTag.UpstreamDeletedAt))
+
+	histories, err := resolver.TagHistory(ctx, repo, "v1.0.1")
+	r.NoError(err)
+	r.Len(histories, 1)
+	r.NotNil(histories[0].UpstreamDeletedAt)
+	a.True(deletedAt.Equal(*histories[0].UpstreamDeletedAt))
+
+	// v1.0.1 is re-created
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1.0.0": hash, "v1.0.1": hash}, now.Add(2*time.Minute)))
+	a.False(findTag("v1.0.1").IsDeletedUpstream())
+
+	histories, err = resolver.TagHistory(ctx, repo, "v1.0.1")
+	r.NoError(err)
+	r.Len(histories, 2)
+	a.Nil(histories[1].UpstreamDeletedAt)
+
+	// the re-creation at the same object is not a move
+	a.Empty(tagMoves)
+
+	// v1.0.1 is deleted and re-created at another object
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1.0.0": hash}, now.Add(3*time.Minute)))
+	otherHash := Hash{CommitHash: "bbbb", TagHash: "bbbb"}
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{"v1.0.0": hash, "v1.0.1": otherHash}, now.Add(4*time.Minute)))
+
+	r.Len(tagMoves, 1)
+	a.Equal("v1.0.1", tagMoves[0].Tag)
+	a.Equal(hash, tagMoves[0].From)
+	a.Equal(otherHash, tagMoves[0].To)
+}
@@ -0,0 +1,269 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// TagType is the type of a git tag
+type TagType string
+
+const (
+	// TagTypeAnnotated is a tag that points to an annotated tag object
+	TagTypeAnnotated TagType = "annotated"
+
+	// TagTypeLightweight is a tag that points to a commit directly
+	TagTypeLightweight TagType = "lightweight"
+)
+
+// TagSort is the sort order of ListTags
+type TagSort string
+
+const (
+	// TagSortName sorts tags by the names in ascending order
+	TagSortName TagSort = "name"
+
+	// TagSortSemver sorts tags by the semantic versions in descending order.
+	// The tags that are not semantic versions follow in the order of the names.
+	TagSortSemver TagSort = "semver"
+
+	// TagSortDate sorts tags by the tagger dates (annotated tags) or the commit dates (lightweight tags)
+	// in descending order
+	TagSortDate TagSort = "date"
+)
+
+// ParseTagSort parses a sort order string of ListTags
+func ParseTagSort(s string) (TagSort, error) {
+	switch TagSort(strings.ToLower(strings.TrimSpace(s))) {
+	case TagSortName:
+		return TagSortName, nil
+
+	case TagSortSemver:
+		return TagSortSemver, nil
+
+	case TagSortDate:
+		return TagSortDate, nil
+
+	default:
+		return "", fmt.Errorf("unsupported sort order: %s", s)
+	}
+}
+
+// ListTagsParams represents the parameters for ListTags
+type ListTagsParams struct {
+	// Glob is a glob pattern to filter the tags (e.g. "v4.*").
+	// The syntax is the same as path.Match.
+	Glob string
+
+	// Regexp is a regular expression to filter the tags
+	Regexp *regexp.Regexp
+
+	// Sort is the sort order of the tags. Default is TagSortName.
+	// TagSortDate fetches the dates of the tags from GitHub.
+	Sort TagSort
+
+	// Limit is the maximum number of the tags to return. 0 means unlimited.
+	Limit int
+}
+
+// TagInfo represents a tag of a repository with the type and the alias group
+type TagInfo struct {
+	GitTag
+
+	// Type is the type of the tag
+	Type TagType
+
+	// Aliases is the other tags that point to the same commit
+	Aliases []string
+
+	// Date is the tagger date of an annotated tag or the commit date of a lightweight tag.
+	// nil if the dates are not fetched.
+	Date *time.Time
+}
+
+func newTagInfo(gitTag GitTag) TagInfo {
+	tagType := TagTypeAnnotated
+	if gitTag.TagHash == gitTag.CommitHash {
+		tagType = TagTypeLightweight
+	}
+
+	return TagInfo{
+		GitTag:  gitTag,
+		Type:    tagType,
+		Aliases: []string{},
+	}
+}
+
+// fetchTagDates fetches the tagger dates of annotated tags and the commit dates of lightweight tags
+func (r Resolver) fetchTagDates(repo repository.Repository) (map[string]time.Time, error) {
+	var query struct {
+		Repository struct {
+			Refs struct {
+				Nodes []struct {
+					Name   string
+					Target struct {
+						Tag struct {
+							Tagger *struct {
+								Date time.Time
+							}
+						} `graphql:"... on Tag"`
+						Commit struct {
+							CommittedDate time.Time
+						} `graphql:"... on Commit"`
+					}
+				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				}
+			} `graphql:"refs(refPrefix:\"refs/tags/\", first: $first, after: $after)"`
+		} `graphql:"repository(owner:$owner, name:$name)"`
+	}
+
+	variables := map[string]interface{}{
+		"owner": graphql.String(repo.Owner),
+		"name":  graphql.String(repo.Name),
+		"first": graphql.Int(maxPageSize),
+		"after": graphql.String("null"),
+	}
+	dates := map[string]time.Time{}
+
+	r.logger.Debug("fetching tag dates", slog.String("repo", ToRepoID(repo)))
+
+	for {
+		if err := r.gqlClient.Query("tag_date", &query, variables); err != nil {
+			return nil, fmt.Errorf("error fetching tag dates: %w", err)
+		}
+
+		for _, node := range query.Repository.Refs.Nodes {
+			if node.Target.Tag.Tagger != nil {
+				dates[node.Name] = node.Target.Tag.Tagger.Date
+			} else if !node.Target.Commit.CommittedDate.IsZero() {
+				dates[node.Name] = node.Target.Commit.CommittedDate
+			}
+		}
+
+		if !query.Repository.Refs.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["after"] = graphql.String(query.Repository.Refs.PageInfo.EndCursor)
+	}
+
+	return dates, nil
+}
+
+// sortTagInfos sorts tags in the specified order
+func sortTagInfos(tagInfos []TagInfo, order TagSort) {
+	switch order {
+	case TagSortSemver:
+		versions := make(map[string]*Version, len(tagInfos))
+		for _, tagInfo := range tagInfos {
+			if v, err := ParseVersion(tagInfo.Tag); err == nil {
+				versions[tagInfo.Tag] = v
+			}
+		}
+
+		sort.SliceStable(tagInfos, func(i, j int) bool {
+			return newerVersionFirst(versions[tagInfos[i].Tag], versions[tagInfos[j].Tag])
+		})
+
+	case TagSortDate:
+		sort.SliceStable(tagInfos, func(i, j int) bool {
+			di, dj := tagInfos[i].Date, tagInfos[j].Date
+
+			if di == nil || dj == nil {
+				return di != nil && dj == nil
+			}
+
+			return di.After(*dj)
+		})
+
+	default:
+		sort.SliceStable(tagInfos, func(i, j int) bool {
+			return tagInfos[i].Tag < tagInfos[j].Tag
+		})
+	}
+}
+
+// ListTags lists the tags of a repository with the hashes, the types and the alias groups
+func (r *Resolver) ListTags(ctx context.Context, repo repository.Repository, params *ListTagsParams) ([]TagInfo, error) {
+	if params == nil {
+		params = &ListTagsParams{}
+	}
+
+	repoID := ToRepoID(repo)
+	r.logger.Debug("listing tags", slog.String("repo", repoID))
+
+	if params.Glob != "" {
+		if _, err := path.Match(params.Glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern (%s): %w", params.Glob, err)
+		}
+	}
+
+	if err := r.touchRepo(ctx, repoID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	gitTags, err := r.listTags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	// the alias groups consist of all the tags regardless of the filters
+	commitToTags := map[string][]string{}
+	for _, gitTag := range gitTags {
+		commitToTags[gitTag.CommitHash] = append(commitToTags[gitTag.CommitHash], gitTag.Tag)
+	}
+
+	var dates map[string]time.Time
+	if params.Sort == TagSortDate {
+		dates, err = r.fetchTagDates(repo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tagInfos := []TagInfo{}
+	for _, gitTag := range gitTags {
+		if params.Glob != "" {
+			if matched, _ := path.Match(params.Glob, gitTag.Tag); !matched {
+				continue
+			}
+		}
+
+		if params.Regexp != nil && !params.Regexp.MatchString(gitTag.Tag) {
+			continue
+		}
+
+		tagInfo := newTagInfo(gitTag)
+		for _, tag := range commitToTags[gitTag.CommitHash] {
+			if tag != gitTag.Tag {
+				tagInfo.Aliases = append(tagInfo.Aliases, tag)
+			}
+		}
+
+		if date, ok := dates[gitTag.Tag]; ok {
+			tagInfo.Date = &date
+		}
+
+		tagInfos = append(tagInfos, tagInfo)
+	}
+
+	sortTagInfos(tagInfos, params.Sort)
+
+	if params.Limit > 0 && len(tagInfos) > params.Limit {
+		tagInfos = tagInfos[:params.Limit]
+	}
+
+	return tagInfos, nil
+}
@@ -0,0 +1,100 @@
+package resolver
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_ListTags(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	resolver := newTestResolver(t, nil)
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+	commitA := strings.Repeat("a", 40)
+	commitB := strings.Repeat("b", 40)
+	annotatedTagHash := strings.Repeat("c", 40)
+
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{
+		"v4":      {CommitHash: commitA, TagHash: commitA},
+		"v4.1.6":  {CommitHash: commitA, TagHash: annotatedTagHash},
+		"v4.1.10": {CommitHash: commitB, TagHash: commitB},
+		"nightly": {CommitHash: commitB, TagHash: commitB},
+	}, time.Now()))
+
+	tagNames := func(tagInfos []TagInfo) []string {
+		names := []string{}
+		for _, tagInfo := range tagInfos {
+			names = append(names, tagInfo.Tag)
+		}
+
+		return names
+	}
+
+	tagInfos, err := resolver.ListTags(ctx, repo, nil)
+	r.NoError(err)
+	a.Equal([]string{"nightly", "v4", "v4.1.10", "v4.1.6"}, tagNames(tagInfos))
+	a.Equal(TagTypeLightweight, tagInfos[1].Type)
+	a.Equal([]string{"v4.1.6"}, tagInfos[1].Aliases)
+	a.Equal(TagTypeAnnotated, tagInfos[3].Type)
+	a.Equal([]string{"v4"}, tagInfos[3].Aliases)
+	a.Nil(tagInfos[3].Date)
+
+	tagInfos, err = resolver.ListTags(ctx, repo, &ListTagsParams{Sort: TagSortSemver})
+	r.NoError(err)
+	// a partial version such as v4 is compared as v4.0.0
+	a.Equal([]string{"v4.1.10", "v4.1.6", "v4", "nightly"}, tagNames(tagInfos))
+
+	tagInfos, err = resolver.ListTags(ctx, repo, &ListTagsParams{Glob: "v4.*", Sort: TagSortSemver, Limit: 1})
+	r.NoError(err)
+	a.Equal([]string{"v4.1.10"}, tagNames(tagInfos))
+
+	tagInfos, err = resolver.ListTags(ctx, repo, &ListTagsParams{Regexp: regexp.MustCompile(`^v\d+$`)})
+	r.NoError(err)
+	a.Equal([]string{"v4"}, tagNames(tagInfos))
+
+	_, err = resolver.ListTags(ctx, repo, &ListTagsParams{Glob: "["})
+	a.Error(err)
+}
+
+func TestResolver_ListTags_AliasExpired(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	h1 := strings.Repeat("1", 40)
+	h2 := strings.Repeat("2", 40)
+	taghashMap := map[string]Hash{
+		"v1.0.0": {CommitHash: h1, TagHash: h1},
+		"v1.1.0": {CommitHash: h2, TagHash: h2},
+		"v1":     {CommitHash: h2, TagHash: h2},
+	}
+
+	fake := newFakeGraphQL()
+	fake.setTags("owner/a", taghashMap)
+
+	resolver := newTestResolver(t, &Params{Client: fake.client(t), CacheTTL: *NewCacheTTL(time.Hour)})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	// the records of the alias tags expire earlier than v1.0.0
+	r.NoError(resolver.storeTags(ctx, repo, taghashMap, time.Now().Add(-30*time.Minute)))
+
+	tagInfos, err := resolver.ListTags(ctx, repo, nil)
+	r.NoError(err)
+	r.Len(tagInfos, 3)
+	a.Equal(1, fake.queries("owner/a"))
+
+	for _, tagInfo := range tagInfos {
+		if tagInfo.Tag == "v1.1.0" {
+			a.Equal([]string{"v1"}, tagInfo.Aliases)
+		}
+	}
+}
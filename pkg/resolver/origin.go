@@ -0,0 +1,163 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	graphql "github.com/cli/shurcooL-graphql"
+	gitdescribe "github.com/thombashi/gh-git-describe/pkg/executor"
+)
+
+// OriginSource is where the reachability of a commit is confirmed
+type OriginSource string
+
+const (
+	// OriginSourceTagCache is the tag list in the cache database
+	OriginSourceTagCache OriginSource = "tagCache"
+
+	// OriginSourceGit is the git clone cache of the repository
+	OriginSourceGit OriginSource = "git"
+
+	// OriginSourceGraphQL is the commit history of the default branch via the GitHub GraphQL API
+	OriginSourceGraphQL OriginSource = "graphql"
+)
+
+// Origin represents the result of verifying that a commit belongs to a repository
+type Origin struct {
+	// RepoID is the GitHub repository ID formatted as "owner/name"
+	RepoID string
+
+	// CommitHash is the verified commit hash
+	CommitHash string
+
+	// Reachable is true if the commit is reachable from at least one branch or tag of the repository.
+	// False means the commit may be an impostor commit that is served from a fork in the fork network.
+	Reachable bool
+
+	// Ref is the fully qualified name of the branch or the tag that the commit is reachable from.
+	// Empty if the commit is not reachable.
+	Ref string
+
+	// Source is where the reachability is confirmed. Empty if the commit is not reachable.
+	Source OriginSource
+}
+
+// findContainingRefFromGitObj returns a branch or a tag that contains the commit in the git clone cache.
+// It returns an empty string if no ref contains the commit or the commit does not exist in the clone.
+func (r Resolver) findContainingRefFromGitObj(ctx context.Context, repoID, hash string) string {
+	output, err := r.gdExecutor.RunGitContext(ctx, &gitdescribe.RepoCloneParams{
+		RepoID:   repoID,
+		CacheTTL: r.cacheTTL.GitFileTTL,
+	}, "for-each-ref", "--contains", hash, "--count=1", "--format=%(refname)", refsHeadsPrefix, refsTagsPrefix)
+	if err != nil {
+		r.logger.Debug("the commit is not found in the git clone cache",
+			slog.String("repo", repoID),
+			slog.String("commit", hash),
+			slog.String("error", err.Error()))
+		return ""
+	}
+
+	return strings.TrimSpace(output)
+}
+
+// isReachableFromDefaultBranch returns true if the commit is an ancestor of the default branch or the head of it.
+// It compares the default branch with the commit via the GitHub GraphQL API.
+func (r Resolver) isReachableFromDefaultBranch(repo repository.Repository, hash string) (string, bool, error) {
+	var query struct {
+		Repository struct {
+			DefaultBranchRef *struct {
+				Name    string
+				Compare *struct {
+					Status string
+				} `graphql:"compare(headRef: $headRef)"`
+			}
+		} `graphql:"repository(owner:$owner, name:$name)"`
+	}
+
+	variables := map[string]interface{}{
+		"owner":   graphql.String(repo.Owner),
+		"name":    graphql.String(repo.Name),
+		"headRef": graphql.String(hash),
+	}
+
+	if err := r.refreshClient.Query("default_branch_compare", &query, variables); err != nil {
+		return "", false, fmt.Errorf("error comparing the default branch with %s: %w", hash, err)
+	}
+
+	ref := query.Repository.DefaultBranchRef
+	if ref == nil || ref.Compare == nil {
+		return "", false, nil
+	}
+
+	// BEHIND means the commit is an ancestor of the default branch
+	switch ref.Compare.Status {
+	case "BEHIND", "IDENTICAL":
+		return refsHeadsPrefix + ref.Name, true, nil
+
+	default:
+		return "", false, nil
+	}
+}
+
+// VerifyOriginContext verifies that a commit is reachable from at least one branch or tag of the repository.
+// GitHub serves the commits of the forks under the name of the parent repository,
+// so a commit hash that is not reachable may point to code that never landed in the repository.
+// The reachability is checked in the order of the tag cache, the git clone cache and the default branch history.
+func (r *Resolver) VerifyOriginContext(ctx context.Context, repo repository.Repository, hash string) (*Origin, error) {
+	if !IsSHA(hash) {
+		return nil, fmt.Errorf("invalid SHA: %s", hash)
+	}
+
+	repoID := ToRepoID(repo)
+	origin := &Origin{
+		RepoID:     repoID,
+		CommitHash: hash,
+	}
+
+	r.logger.Debug("verifying the origin of a commit", slog.String("repo", repoID), slog.String("commit", hash))
+
+	if err := r.touchRepo(ctx, repoID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	gitTags, err := r.listTags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, gitTag := range gitTags {
+		if gitTag.CommitHash == hash || gitTag.TagHash == hash {
+			origin.Reachable = true
+			origin.Ref = refsTagsPrefix + gitTag.Tag
+			origin.Source = OriginSourceTagCache
+
+			return origin, nil
+		}
+	}
+
+	if ref := r.findContainingRefFromGitObj(ctx, repoID, hash); ref != "" {
+		origin.Reachable = true
+		origin.Ref = ref
+		origin.Source = OriginSourceGit
+
+		return origin, nil
+	}
+
+	// the commit may be pushed after the repository is cloned
+	ref, reachable, err := r.isReachableFromDefaultBranch(repo, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if reachable {
+		origin.Reachable = true
+		origin.Ref = ref
+		origin.Source = OriginSourceGraphQL
+	}
+
+	return origin, nil
+}
@@ -0,0 +1,91 @@
+package resolver
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_VerifyOriginContext(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	gitRepo := newTestGitRepo(t)
+	gitRepo.commit("initial")
+	tagged := gitRepo.commit("release")
+	gitRepo.git("tag", "v1.0.0")
+	onBranch := gitRepo.commit("feature")
+
+	// a commit that is not reachable from any ref such as a commit of a fork
+	gitRepo.git("checkout", "--quiet", "-b", "fork")
+	impostor := gitRepo.commit("malicious")
+	gitRepo.git("checkout", "--quiet", "main")
+	gitRepo.git("branch", "-D", "fork")
+
+	resolver := newTestResolver(t, &Params{GitDescExecutor: gitRepo.executor()})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{
+		"v1.0.0": {CommitHash: tagged, TagHash: tagged},
+	}, time.Now()))
+
+	origin, err := resolver.VerifyOriginContext(ctx, repo, tagged)
+	r.NoError(err)
+	a.True(origin.Reachable)
+	a.Equal("refs/tags/v1.0.0", origin.Ref)
+	a.Equal(OriginSourceTagCache, origin.Source)
+
+	origin, err = resolver.VerifyOriginContext(ctx, repo, onBranch)
+	r.NoError(err)
+	a.True(origin.Reachable)
+	a.Equal("refs/heads/main", origin.Ref)
+	a.Equal(OriginSourceGit, origin.Source)
+
+	a.Empty(resolver.findContainingRefFromGitObj(ctx, "owner/a", impostor))
+	a.Empty(resolver.findContainingRefFromGitObj(ctx, "owner/a", strings.Repeat("f", 40)))
+
+	_, err = resolver.VerifyOriginContext(ctx, repo, "v1.0.0")
+	a.Error(err)
+}
+
+func TestResolver_VerifyOriginContext_AliasTagExpired(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	h1 := strings.Repeat("1", 40)
+	h2 := strings.Repeat("2", 40)
+	taghashMap := map[string]Hash{
+		"v1.0.0": {CommitHash: h1, TagHash: h1},
+		"v1.1.0": {CommitHash: h2, TagHash: h2},
+		"v1":     {CommitHash: h2, TagHash: h2},
+	}
+
+	fake := newFakeGraphQL()
+	fake.setTags("owner/a", taghashMap)
+
+	// neither the git clone nor the default branch history is available
+	resolver := newTestResolver(t, &Params{
+		Client:          fake.client(t),
+		GitDescExecutor: localGitExecutor{dirPath: filepath.Join(t.TempDir(), "not-exist")},
+		CacheTTL:        *NewCacheTTL(time.Hour),
+	})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	// the records of the alias tags are expired and pruned
+	r.NoError(resolver.storeTags(ctx, repo, taghashMap, time.Now().Add(-30*time.Minute)))
+	_, err := resolver.PruneCache(ctx, nil)
+	r.NoError(err)
+
+	origin, err := resolver.VerifyOriginContext(ctx, repo, h2)
+	r.NoError(err)
+	a.True(origin.Reachable)
+	a.Equal(OriginSourceTagCache, origin.Source)
+}
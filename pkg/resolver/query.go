@@ -0,0 +1,114 @@
+package resolver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"gorm.io/gorm"
+)
+
+// VersionQueryParams represents the parameters for ResolveVersionQuery
+type VersionQueryParams struct {
+	// ExcludePrerelease is a flag to exclude the pre-release versions from the candidates
+	ExcludePrerelease bool
+}
+
+// listTags returns the unexpired tags of a repository that exist upstream.
+// The tags are fetched from GitHub if the cached tag list of the repository is expired (GitRepo.TagListExpiredAt),
+// because a part of the tags may have expired earlier than the others.
+func (r *Resolver) listTags(ctx context.Context, repo repository.Repository) ([]GitTag, error) {
+	repoID := ToRepoID(repo)
+	now := time.Now()
+
+	var gitRepo GitRepo
+	err := r.db.WithContext(ctx).Where(&GitRepo{RepoID: repoID}).Limit(1).Find(&gitRepo).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to select the repository record: %w", err)
+	}
+
+	if gitRepo.TagListExpiredAt.Before(now) {
+		r.logger.Debug("the cached tag list is expired", slog.String("repo", repoID))
+
+		if err := r.updateCacheDB(ctx, r.gqlClient, repo, &now); err != nil {
+			return nil, fmt.Errorf("failed to update the cache database: %w", err)
+		}
+	}
+
+	var gitTags []GitTag
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Where(&GitTag{RepoID: repoID, Source: SourceGraphQL}).
+			Where(whereNotExpired, now).
+			Where(whereUpstreamExist).
+			Order("tag").
+			Find(&gitTags).Error
+	}, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to select records: %w", err)
+	}
+
+	return gitTags, nil
+}
+
+// ResolveVersionQuery resolves a semantic version range (e.g. "^4", "~4.1", ">=4.1.0 <5")
+// or the "latest" keyword to the highest version tag that satisfies it.
+// Only the tags that have all the version core components (e.g. v4.1.6 rather than v4) are the candidates.
+// The returned GitTag has the concrete tag name that is chosen.
+func (r *Resolver) ResolveVersionQuery(ctx context.Context, repo repository.Repository, query string, params *VersionQueryParams) (*GitTag, error) {
+	if params == nil {
+		params = &VersionQueryParams{}
+	}
+
+	constraint, err := ParseVersionConstraint(query)
+	if err != nil {
+		return nil, err
+	}
+
+	repoID := ToRepoID(repo)
+	r.logger.Debug("resolving a version query", slog.String("repo", repoID), slog.String("query", query))
+
+	if err := r.touchRepo(ctx, repoID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	gitTags, err := r.listTags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *GitTag
+	var bestVersion *Version
+
+	for i, gitTag := range gitTags {
+		v, err := ParseVersion(gitTag.Tag)
+		if err != nil || v.Parts < 3 {
+			continue
+		}
+
+		if params.ExcludePrerelease && v.IsPrerelease() {
+			continue
+		}
+
+		if !constraint.Match(*v) {
+			continue
+		}
+
+		// the tags are in the order of the names: the first one wins if the versions have the same precedence
+		if bestVersion == nil || v.Compare(*bestVersion) > 0 {
+			best = &gitTags[i]
+			bestVersion = v
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no tag satisfies the version query: %s", query)
+	}
+
+	r.logger.Debug("resolved a version query", slog.String("query", query), slog.String("tag", best.Tag))
+
+	return best, nil
+}
@@ -0,0 +1,96 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_ResolveVersionQuery(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	resolver := newTestResolver(t, nil)
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	tags := []string{"v4", "v4.1", "v4.1.5", "v4.1.6", "v4.2.0", "v5.0.0-rc.1", "v3.6.0", "sdk/go/v1.2.3", "sdk/go/v1.3.0", "nightly"}
+	taghashMap := map[string]Hash{}
+	for i, tag := range tags {
+		hash := strings.Repeat(string(rune('a'+i)), 40)
+		taghashMap[tag] = Hash{CommitHash: hash, TagHash: hash}
+	}
+	r.NoError(resolver.storeTags(ctx, repo, taghashMap, time.Now()))
+
+	testCases := []struct {
+		query             string
+		excludePrerelease bool
+		want              string
+	}{
+		{query: "latest", want: "v5.0.0-rc.1"},
+		{query: "latest", excludePrerelease: true, want: "v4.2.0"},
+		{query: "^4", want: "v4.2.0"},
+		{query: "~4.1", want: "v4.1.6"},
+		{query: ">=4.1.0 <4.1.6", want: "v4.1.5"},
+		{query: "^3", want: "v3.6.0"},
+		{query: "sdk/go/latest", want: "sdk/go/v1.3.0"},
+		{query: "sdk/go/~1.2", want: "sdk/go/v1.2.3"},
+	}
+
+	for _, tc := range testCases {
+		gitTag, err := resolver.ResolveVersionQuery(ctx, repo, tc.query, &VersionQueryParams{
+			ExcludePrerelease: tc.excludePrerelease,
+		})
+		r.NoError(err, tc.query)
+		a.Equal(tc.want, gitTag.Tag, tc.query)
+		a.Equal(taghashMap[tc.want].CommitHash, gitTag.CommitHash, tc.query)
+	}
+
+	_, err := resolver.ResolveVersionQuery(ctx, repo, "^6", nil)
+	a.Error(err)
+}
+
+func TestResolver_ResolveVersionQuery_AliasExpired(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	h1 := strings.Repeat("1", 40)
+	h2 := strings.Repeat("2", 40)
+	taghashMap := map[string]Hash{
+		"v1.0.0": {CommitHash: h1, TagHash: h1},
+		"v1.1.0": {CommitHash: h2, TagHash: h2},
+		"v1":     {CommitHash: h2, TagHash: h2},
+	}
+
+	fake := newFakeGraphQL()
+	fake.setTags("owner/a", taghashMap)
+
+	resolver := newTestResolver(t, &Params{Client: fake.client(t), CacheTTL: *NewCacheTTL(time.Hour)})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	// the alias tags (v1.1.0 and v1) are expired and pruned while v1.0.0 is still cached
+	r.NoError(resolver.storeTags(ctx, repo, taghashMap, time.Now().Add(-30*time.Minute)))
+	_, err := resolver.PruneCache(ctx, nil)
+	r.NoError(err)
+
+	gitTags, err := resolver.ListCache(ctx, repo)
+	r.NoError(err)
+	r.Len(gitTags, 1)
+
+	gitTag, err := resolver.ResolveVersionQuery(ctx, repo, "latest", nil)
+	r.NoError(err)
+	a.Equal("v1.1.0", gitTag.Tag)
+	a.Equal(1, fake.queries("owner/a"))
+
+	// the refetched tag list is used until it expires
+	gitTag, err = resolver.ResolveVersionQuery(ctx, repo, "latest", nil)
+	r.NoError(err)
+	a.Equal("v1.1.0", gitTag.Tag)
+	a.Equal(1, fake.queries("owner/a"))
+}
@@ -0,0 +1,331 @@
+package resolver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	graphql "github.com/cli/shurcooL-graphql"
+	gitdescribe "github.com/thombashi/gh-git-describe/pkg/executor"
+	"gorm.io/gorm"
+)
+
+const (
+	refsPrefix       = "refs/"
+	refsTagsPrefix   = "refs/tags/"
+	refsHeadsPrefix  = "refs/heads/"
+	refsPullPrefix   = "refs/pull/"
+	pullHeadSuffix   = "head"
+	pullMergeSuffix  = "merge"
+	pullRefPartCount = 4
+)
+
+// RefType is the type of a git ref
+type RefType string
+
+const (
+	// RefTypeTag is a tag (refs/tags/)
+	RefTypeTag RefType = "tag"
+
+	// RefTypeBranch is a branch (refs/heads/)
+	RefTypeBranch RefType = "branch"
+
+	// RefTypePull is a pull request ref (refs/pull/<number>/head or refs/pull/<number>/merge)
+	RefTypePull RefType = "pull"
+
+	// RefTypeOther is a fully qualified ref of the other namespaces
+	RefTypeOther RefType = "ref"
+)
+
+// ErrRefNotFound is returned when a ref does not exist in the repository
+var ErrRefNotFound = errors.New("the ref is not found")
+
+// ResolvedRef represents a ref resolved to the hashes
+type ResolvedRef struct {
+	// Ref is the fully qualified ref name (e.g. refs/heads/main)
+	Ref string
+
+	// Type is the type of the ref
+	Type RefType
+
+	// CommitHash is the git commit hash that the ref points to
+	CommitHash string
+
+	// TagHash is the git tag hash of an annotated tag.
+	// The same as the CommitHash for the other refs.
+	TagHash string
+}
+
+// ToGitTag converts a ref to a GitTag that has the hashes of the ref
+func (r ResolvedRef) ToGitTag() GitTag {
+	return GitTag{
+		Tag:        strings.TrimPrefix(r.Ref, refsTagsPrefix),
+		CommitHash: r.CommitHash,
+		TagHash:    r.TagHash,
+	}
+}
+
+func newTagRef(gitTag GitTag) *ResolvedRef {
+	return &ResolvedRef{
+		Ref:        refsTagsPrefix + gitTag.Tag,
+		Type:       RefTypeTag,
+		CommitHash: gitTag.CommitHash,
+		TagHash:    gitTag.TagHash,
+	}
+}
+
+// refTypeOf returns the type of a fully qualified ref
+func refTypeOf(ref string) RefType {
+	switch {
+	case strings.HasPrefix(ref, refsTagsPrefix):
+		return RefTypeTag
+
+	case strings.HasPrefix(ref, refsHeadsPrefix):
+		return RefTypeBranch
+
+	case strings.HasPrefix(ref, refsPullPrefix):
+		return RefTypePull
+
+	default:
+		return RefTypeOther
+	}
+}
+
+// parsePullRef parses a pull request ref such as refs/pull/123/head
+func parsePullRef(ref string) (int, string, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != pullRefPartCount || (parts[3] != pullHeadSuffix && parts[3] != pullMergeSuffix) {
+		return 0, "", fmt.Errorf("invalid pull request ref: %s", ref)
+	}
+
+	number, err := strconv.Atoi(parts[2])
+	if err != nil || number <= 0 {
+		return 0, "", fmt.Errorf("invalid pull request number: %s", ref)
+	}
+
+	return number, parts[3], nil
+}
+
+// fetchPullRefHash fetches the head commit or the merge commit of a pull request
+func (r Resolver) fetchPullRefHash(repo repository.Repository, ref string) (string, error) {
+	number, suffix, err := parsePullRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	var query struct {
+		Repository struct {
+			PullRequest *struct {
+				HeadRefOid           string
+				PotentialMergeCommit *struct {
+					Oid string
+				}
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner:$owner, name:$name)"`
+	}
+
+	variables := map[string]interface{}{
+		"owner":  graphql.String(repo.Owner),
+		"name":   graphql.String(repo.Name),
+		"number": graphql.Int(number),
+	}
+
+	if err := r.refreshClient.Query("pull_ref", &query, variables); err != nil {
+		return "", fmt.Errorf("error fetching a pull request ref (%s): %w", ref, err)
+	}
+
+	pr := query.Repository.PullRequest
+	if pr == nil {
+		return "", fmt.Errorf("%w: %s", ErrRefNotFound, ref)
+	}
+
+	if suffix == pullHeadSuffix {
+		return pr.HeadRefOid, nil
+	}
+
+	// a pull request that has conflicts or is closed does not have a merge commit
+	if pr.PotentialMergeCommit == nil {
+		return "", fmt.Errorf("%w: %s", ErrRefNotFound, ref)
+	}
+
+	return pr.PotentialMergeCommit.Oid, nil
+}
+
+// fetchRefHash fetches the commit hash that a fully qualified ref points to
+func (r Resolver) fetchRefHash(repo repository.Repository, ref string) (string, error) {
+	var query struct {
+		Repository struct {
+			Ref *struct {
+				Target struct {
+					CommitResourcePath string
+				}
+			} `graphql:"ref(qualifiedName: $qualifiedName)"`
+		} `graphql:"repository(owner:$owner, name:$name)"`
+	}
+
+	variables := map[string]interface{}{
+		"owner":         graphql.String(repo.Owner),
+		"name":          graphql.String(repo.Name),
+		"qualifiedName": graphql.String(ref),
+	}
+
+	if err := r.refreshClient.Query("ref_hash", &query, variables); err != nil {
+		return "", fmt.Errorf("error fetching a ref (%s): %w", ref, err)
+	}
+
+	if query.Repository.Ref == nil {
+		return "", fmt.Errorf("%w: %s", ErrRefNotFound, ref)
+	}
+
+	return extractShaFromCommitResourcePath(query.Repository.Ref.Target.CommitResourcePath)
+}
+
+// resolveQualifiedRef resolves a fully qualified ref other than the tags.
+// The refs are cached with the GitBranchTTL because they move more often than the tags.
+// The HTTP query cache is bypassed for the same reason.
+func (r *Resolver) resolveQualifiedRef(ctx context.Context, repo repository.Repository, ref string, now time.Time) (*ResolvedRef, error) {
+	repoID := ToRepoID(repo)
+	refType := refTypeOf(ref)
+
+	var gitRef GitRef
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Where(&GitRef{RepoID: repoID, Ref: ref}).Where(whereNotExpired, now).First(&gitRef).Error
+	}, &sql.TxOptions{ReadOnly: true})
+	if err == nil {
+		return &ResolvedRef{Ref: ref, Type: refType, CommitHash: gitRef.CommitHash, TagHash: gitRef.CommitHash}, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to select record: %w", err)
+	}
+
+	r.logger.Debug("fetching a ref", slog.String("repo", repoID), slog.String("ref", ref))
+
+	var commitHash string
+	if refType == RefTypePull {
+		commitHash, err = r.fetchPullRefHash(repo, ref)
+	} else {
+		commitHash, err = r.fetchRefHash(repo, ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	newGitRef := &GitRef{
+		RepoID:     repoID,
+		Ref:        ref,
+		Type:       refType,
+		CommitHash: commitHash,
+		FetchedAt:  now,
+		ExpiredAt:  now.Add(r.cacheTTL.GitBranchTTL),
+	}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(&GitRef{RepoID: repoID, Ref: ref}).Delete(&GitRef{}).Error; err != nil {
+			return fmt.Errorf("failed to delete stale records: %w", err)
+		}
+
+		if err := tx.Create(newGitRef).Error; err != nil {
+			return fmt.Errorf("failed to create a record: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update the database: %w", err)
+	}
+
+	return &ResolvedRef{Ref: ref, Type: refType, CommitHash: commitHash, TagHash: commitHash}, nil
+}
+
+// ResolveRefContext resolves a tag, a branch or a fully qualified ref (e.g. refs/pull/123/head) to the hashes.
+// A name that is not qualified is resolved in the same precedence as git: tags first and then branches.
+// If the name matches neither, it falls back to ResolveFromTagContext.
+func (r *Resolver) ResolveRefContext(ctx context.Context, repo repository.Repository, ref string) (*ResolvedRef, error) {
+	ref = strings.TrimSpace(ref)
+	if err := validateRevision(ref); err != nil {
+		return nil, err
+	}
+
+	repoID := ToRepoID(repo)
+	now := time.Now()
+
+	r.logger.Debug("resolving a ref", slog.String("repo", repoID), slog.String("from", ref))
+
+	if strings.HasPrefix(ref, refsTagsPrefix) {
+		gitTag, err := r.ResolveFromTagContext(ctx, repo, strings.TrimPrefix(ref, refsTagsPrefix))
+		if err != nil {
+			return nil, err
+		}
+
+		return newTagRef(*gitTag), nil
+	}
+
+	if err := r.touchRepo(ctx, repoID, now); err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(ref, refsPrefix) {
+		return r.resolveQualifiedRef(ctx, repo, ref, now)
+	}
+
+	gitTags, err := r.listTags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, gitTag := range gitTags {
+		if gitTag.Tag == ref {
+			return newTagRef(gitTag), nil
+		}
+	}
+
+	resolvedRef, err := r.resolveQualifiedRef(ctx, repo, refsHeadsPrefix+ref, now)
+	if err == nil {
+		return resolvedRef, nil
+	} else if !errors.Is(err, ErrRefNotFound) {
+		return nil, err
+	}
+
+	// neither a tag nor a branch: such as a describe-style string or a tag deleted upstream
+	gitTag, err := r.ResolveFromTagContext(ctx, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTagRef(*gitTag), nil
+}
+
+// BranchesContaining lists the branches whose history includes the commit, like 'git branch --contains'.
+// The branches are sorted by the names.
+// It uses the git clone cache of the repository.
+func (r Resolver) BranchesContaining(ctx context.Context, repo repository.Repository, commit string) ([]string, error) {
+	repoID := ToRepoID(repo)
+
+	commitHash, err := r.resolveCommitFromGitObj(ctx, repoID, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug("listing branches containing a commit", slog.String("repo", repoID), slog.String("commit", commitHash))
+
+	output, err := r.gdExecutor.RunGitContext(ctx, &gitdescribe.RepoCloneParams{
+		RepoID:   repoID,
+		CacheTTL: r.cacheTTL.GitFileTTL,
+	}, "for-each-ref", "--contains", commitHash, "--format=%(refname:lstrip=2)", "--sort=refname", refsHeadsPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if branch := strings.TrimSpace(line); branch != "" {
+			branches = append(branches, branch)
+		}
+	}
+
+	return branches, nil
+}
@@ -0,0 +1,171 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePullRef(t *testing.T) {
+	a := assert.New(t)
+
+	testCases := []struct {
+		ref        string
+		wantNumber int
+		wantSuffix string
+		wantErr    bool
+	}{
+		{ref: "refs/pull/123/head", wantNumber: 123, wantSuffix: "head"},
+		{ref: "refs/pull/1/merge", wantNumber: 1, wantSuffix: "merge"},
+		{ref: "refs/pull/123", wantErr: true},
+		{ref: "refs/pull/abc/head", wantErr: true},
+		{ref: "refs/pull/0/head", wantErr: true},
+		{ref: "refs/pull/123/base", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		number, suffix, err := parsePullRef(tc.ref)
+		if tc.wantErr {
+			a.Error(err, tc.ref)
+			continue
+		}
+
+		a.NoError(err, tc.ref)
+		a.Equal(tc.wantNumber, number, tc.ref)
+		a.Equal(tc.wantSuffix, suffix, tc.ref)
+	}
+}
+
+func TestResolver_ResolveRefContext(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	resolver := newTestResolver(t, nil)
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+	now := time.Now()
+
+	tagHash := strings.Repeat("a", 40)
+	commitHash := strings.Repeat("b", 40)
+	r.NoError(resolver.storeTags(ctx, repo, map[string]Hash{
+		"v1.0.0": {CommitHash: commitHash, TagHash: tagHash},
+	}, now))
+
+	branchHash := strings.Repeat("c", 40)
+	pullHash := strings.Repeat("d", 40)
+	for _, gitRef := range []GitRef{
+		{Ref: "refs/heads/main", Type: RefTypeBranch, CommitHash: branchHash},
+		{Ref: "refs/heads/release/1.x", Type: RefTypeBranch, CommitHash: branchHash},
+		{Ref: "refs/pull/123/head", Type: RefTypePull, CommitHash: pullHash},
+	} {
+		gitRef.RepoID = ToRepoID(repo)
+		gitRef.FetchedAt = now
+		gitRef.ExpiredAt = now.Add(time.Hour)
+		r.NoError(resolver.db.Create(&gitRef).Error)
+	}
+
+	testCases := []struct {
+		ref            string
+		wantRef        string
+		wantType       RefType
+		wantCommitHash string
+		wantTagHash    string
+	}{
+		{ref: "v1.0.0", wantRef: "refs/tags/v1.0.0", wantType: RefTypeTag, wantCommitHash: commitHash, wantTagHash: tagHash},
+		{ref: "refs/tags/v1.0.0", wantRef: "refs/tags/v1.0.0", wantType: RefTypeTag, wantCommitHash: commitHash, wantTagHash: tagHash},
+		{ref: "main", wantRef: "refs/heads/main", wantType: RefTypeBranch, wantCommitHash: branchHash, wantTagHash: branchHash},
+		{ref: "release/1.x", wantRef: "refs/heads/release/1.x", wantType: RefTypeBranch, wantCommitHash: branchHash, wantTagHash: branchHash},
+		{ref: "refs/heads/main", wantRef: "refs/heads/main", wantType: RefTypeBranch, wantCommitHash: branchHash, wantTagHash: branchHash},
+		{ref: "refs/pull/123/head", wantRef: "refs/pull/123/head", wantType: RefTypePull, wantCommitHash: pullHash, wantTagHash: pullHash},
+	}
+
+	for _, tc := range testCases {
+		resolvedRef, err := resolver.ResolveRefContext(ctx, repo, tc.ref)
+		r.NoError(err, tc.ref)
+		a.Equal(tc.wantRef, resolvedRef.Ref, tc.ref)
+		a.Equal(tc.wantType, resolvedRef.Type, tc.ref)
+		a.Equal(tc.wantCommitHash, resolvedRef.CommitHash, tc.ref)
+		a.Equal(tc.wantTagHash, resolvedRef.TagHash, tc.ref)
+	}
+
+	_, err := resolver.ResolveRefContext(ctx, repo, "--all")
+	a.Error(err)
+
+	// the expired refs are pruned
+	threshold := now.Add(2 * time.Hour)
+	_, err = resolver.PruneCache(ctx, &threshold)
+	r.NoError(err)
+
+	var count int64
+	r.NoError(resolver.db.Model(&GitRef{}).Count(&count).Error)
+	a.Zero(count)
+}
+
+func TestResolver_BranchesContaining(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	gitRepo := newTestGitRepo(t)
+	base := gitRepo.commit("initial")
+	fix := gitRepo.commit("fix")
+	gitRepo.git("branch", "release/1.x")
+	gitRepo.git("checkout", "--quiet", "-b", "release/0.x", base)
+	gitRepo.commit("backport without the fix")
+
+	resolver := newTestResolver(t, &Params{GitDescExecutor: gitRepo.executor()})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+
+	branches, err := resolver.BranchesContaining(ctx, repo, fix)
+	r.NoError(err)
+	a.Equal([]string{"main", "release/1.x"}, branches)
+
+	branches, err = resolver.BranchesContaining(ctx, repo, base)
+	r.NoError(err)
+	a.Equal([]string{"main", "release/0.x", "release/1.x"}, branches)
+
+	_, err = resolver.BranchesContaining(ctx, repo, "--all")
+	a.Error(err)
+}
+
+func TestResolver_ResolveRefContext_AliasTagExpired(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	tagHash := strings.Repeat("a", 40)
+	branchHash := strings.Repeat("b", 40)
+	taghashMap := map[string]Hash{
+		"v1.0.0": {CommitHash: tagHash, TagHash: tagHash},
+		"stable": {CommitHash: tagHash, TagHash: tagHash},
+	}
+
+	fake := newFakeGraphQL()
+	fake.setTags("owner/a", taghashMap)
+
+	resolver := newTestResolver(t, &Params{Client: fake.client(t), CacheTTL: *NewCacheTTL(time.Hour)})
+	repo := repository.Repository{Owner: "owner", Name: "a"}
+	now := time.Now()
+
+	// the records of the alias tags are expired while a branch of the same name is cached
+	r.NoError(resolver.storeTags(ctx, repo, taghashMap, now.Add(-30*time.Minute)))
+	r.NoError(resolver.db.Create(&GitRef{
+		RepoID:     ToRepoID(repo),
+		Ref:        "refs/heads/stable",
+		Type:       RefTypeBranch,
+		CommitHash: branchHash,
+		FetchedAt:  now,
+		ExpiredAt:  now.Add(time.Hour),
+	}).Error)
+
+	resolvedRef, err := resolver.ResolveRefContext(ctx, repo, "stable")
+	r.NoError(err)
+	a.Equal("refs/tags/stable", resolvedRef.Ref)
+	a.Equal(RefTypeTag, resolvedRef.Type)
+	a.Equal(tagHash, resolvedRef.CommitHash)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/glebarez/sqlite"
 	gitdescribe "github.com/thombashi/gh-git-describe/pkg/executor"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	gormlogger "gorm.io/gorm/logger"
 )
 
@@ -68,17 +69,29 @@ type Hash struct {
 }
 
 type Resolver struct {
-	gqlClient  *api.GraphQLClient
-	logger     *slog.Logger
-	db         *gorm.DB
-	cacheTTL   CacheTTL
-	gdExecutor gitdescribe.Executor
+	gqlClient       *api.GraphQLClient
+	refreshClient   *api.GraphQLClient
+	logger          *slog.Logger
+	db              *gorm.DB
+	cacheDirPath    string
+	cacheDBPath     string
+	cacheTTL        CacheTTL
+	maxCacheSize    int64
+	vacuumThreshold int64
+	onTagMove       func(move TagMove)
+	describeOptions DescribeOptions
+	gdExecutor      gitdescribe.Executor
 }
 
 type Params struct {
 	// Client is a GraphQL client
 	Client *api.GraphQLClient
 
+	// RefreshClient is a GraphQL client used by Refresh.
+	// The client should not reuse cached HTTP responses.
+	// If not specified, Client is used.
+	RefreshClient *api.GraphQLClient
+
 	// GitDescExecutor is an executor for the thombashi/gh-git-describe.
 	GitDescExecutor gitdescribe.Executor
 
@@ -103,6 +116,21 @@ type Params struct {
 	// CacheTTL is the time duration settings for the cache
 	CacheTTL CacheTTL
 
+	// MaxCacheSize is the maximum size of the cache database in bytes.
+	// If the cache database exceeds the size, the records of the least recently accessed repositories are evicted.
+	// Zero means unlimited.
+	MaxCacheSize int64
+
+	// OnTagMove is called when a tag is observed pointing to a different object from the last observation.
+	OnTagMove func(move TagMove)
+
+	// VacuumThreshold is the number of deleted records to trigger VACUUM of the cache database.
+	// Zero disables VACUUM after pruning.
+	VacuumThreshold int64
+
+	// DescribeOptions is the options of 'git describe' used when a hash is not found in the tag list
+	DescribeOptions DescribeOptions
+
 	// LogWithPackage is a flag to add module information to the log.
 	LogWithPackage bool
 }
@@ -152,23 +180,54 @@ func New(params *Params) (*Resolver, error) {
 		return nil, fmt.Errorf("failed to open a database: %w", err)
 	}
 
-	if err := db.AutoMigrate(&GitTag{}); err != nil {
+	if err := db.AutoMigrate(&GitTag{}, &GitRepo{}, &GitTagHistory{}, &GitRef{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate the database: %w", err)
 	}
 
+	refreshClient := params.RefreshClient
+	if refreshClient == nil {
+		refreshClient = params.Client
+	}
+
+	r := &Resolver{
+		gqlClient:       params.Client,
+		refreshClient:   refreshClient,
+		gdExecutor:      params.GitDescExecutor,
+		logger:          logger,
+		cacheTTL:        params.CacheTTL,
+		maxCacheSize:    params.MaxCacheSize,
+		vacuumThreshold: params.VacuumThreshold,
+		onTagMove:       params.OnTagMove,
+		describeOptions: params.DescribeOptions,
+		db:              db,
+		cacheDirPath:    cacheDirPath,
+		cacheDBPath:     cacheDBPath,
+	}
+
 	if params.ClearCache {
 		var deletedCount int64
 
-		logger.Debug("mark as delete all the cache records", slog.String("path", cacheDBPath))
+		logger.Debug("delete all the cache records", slog.String("path", cacheDBPath))
 		ctx := context.Background()
 		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			result := tx.Model(&GitTag{}).Where("1 = 1").Delete(&GitTag{})
+			result := tx.Unscoped().Where("1 = 1").Delete(&GitTag{})
 			if result.Error != nil {
 				return fmt.Errorf("failed to delete records: %w", result.Error)
 			}
 
 			deletedCount = result.RowsAffected
 
+			result = tx.Where("1 = 1").Delete(&GitRef{})
+			if result.Error != nil {
+				return fmt.Errorf("failed to delete ref records: %w", result.Error)
+			}
+
+			deletedCount += result.RowsAffected
+
+			if err := tx.Where("1 = 1").Delete(&GitRepo{}).Error; err != nil {
+				return fmt.Errorf("failed to delete repository records: %w", err)
+			}
+
 			return nil
 		})
 		if err != nil {
@@ -176,14 +235,12 @@ func New(params *Params) (*Resolver, error) {
 		}
 
 		logger.Debug("deleted cache records", slog.Int64("count", deletedCount))
-	}
 
-	r := &Resolver{
-		gqlClient:  params.Client,
-		gdExecutor: params.GitDescExecutor,
-		logger:     logger,
-		cacheTTL:   params.CacheTTL,
-		db:         db,
+		if r.shouldVacuum(deletedCount) {
+			if err := r.Vacuum(ctx); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return r, nil
@@ -191,6 +248,10 @@ func New(params *Params) (*Resolver, error) {
 
 // FetchTagAndOID fetches tags and OIDs from a GitHub repository
 func (r Resolver) FetchTagAndOID(repo repository.Repository) (map[string]Hash, error) {
+	return r.fetchTagAndOID(r.gqlClient, repo)
+}
+
+func (r Resolver) fetchTagAndOID(gqlClient *api.GraphQLClient, repo repository.Repository) (map[string]Hash, error) {
 	var query struct {
 		Repository struct {
 			Refs struct {
@@ -220,7 +281,7 @@ func (r Resolver) FetchTagAndOID(repo repository.Repository) (map[string]Hash, e
 
 	r.logger.Debug("fetching tags and oids", slog.String("repo", repoID))
 
-	err := r.gqlClient.Query("tag_hash", &query, variables)
+	err := gqlClient.Query("tag_hash", &query, variables)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching tag and oid: %w", err)
 	}
@@ -244,7 +305,7 @@ func (r Resolver) FetchTagAndOID(repo repository.Repository) (map[string]Hash, e
 			slog.String("repo", repoID),
 			slog.String("cursor", endCursor))
 
-		err := r.gqlClient.Query("tag_hash", &query, variables)
+		err := gqlClient.Query("tag_hash", &query, variables)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching tag and oid: error=%w, cursor=%s", err, endCursor)
 		}
@@ -267,7 +328,11 @@ func (r Resolver) FetchTagAndOID(repo repository.Repository) (map[string]Hash, e
 // PruneCache removes expired records from the cache database.
 // Records are considered expired if the threshold is later than the expired_at field.
 // If the threshold is nil, it uses the current time.
-func (r *Resolver) PruneCache(ctx context.Context, threshold *time.Time) error {
+// Records are deleted physically, and soft-deleted records left by older versions are deleted as well.
+// After pruning, the records of the least recently accessed repositories are evicted
+// if the cache database exceeds the MaxCacheSize.
+// It returns the number of the deleted records, including the evicted records.
+func (r *Resolver) PruneCache(ctx context.Context, threshold *time.Time) (int64, error) {
 	r.logger.Debug("pruning expired records from the cache database")
 
 	if threshold == nil {
@@ -275,24 +340,54 @@ func (r *Resolver) PruneCache(ctx context.Context, threshold *time.Time) error {
 		threshold = &now
 	}
 
+	var prunedCount int64
+
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		result := tx.Model(&GitTag{}).Where(whereExpired, threshold).Delete(&GitTag{})
+		result := tx.Unscoped().Where(whereExpired, threshold).Or(whereSoftDeleted).Delete(&GitTag{})
 		if result.Error != nil {
 			return fmt.Errorf("failed to delete expired records: %w", result.Error)
 		}
 
+		prunedCount = result.RowsAffected
 		r.logger.Debug("deleted expired records", slog.Int64("rows", result.RowsAffected))
 
+		result = tx.Where(whereExpired, threshold).Delete(&GitRef{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete expired ref records: %w", result.Error)
+		}
+
+		prunedCount += result.RowsAffected
+		r.logger.Debug("deleted expired ref records", slog.Int64("rows", result.RowsAffected))
+
+		// the access information is no longer needed for the repositories that do not have any records
+		result = tx.Where("repo_id NOT IN (?)", tx.Unscoped().Model(&GitTag{}).Select("repo_id")).
+			Where("repo_id NOT IN (?)", tx.Model(&GitRef{}).Select("repo_id")).
+			Delete(&GitRepo{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete repository records: %w", result.Error)
+		}
+
 		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("failed to prune cache: %w", err)
+		return 0, fmt.Errorf("failed to prune cache: %w", err)
 	}
 
-	return nil
+	evictedCount, err := r.evictCache(ctx)
+	if err != nil {
+		return prunedCount + evictedCount, fmt.Errorf("failed to evict cache: %w", err)
+	}
+
+	if evictedCount > 0 || r.shouldVacuum(prunedCount) {
+		if err := r.Vacuum(ctx); err != nil {
+			return prunedCount + evictedCount, err
+		}
+	}
+
+	return prunedCount + evictedCount, nil
 }
 
-func (r *Resolver) updateCacheDB(ctx context.Context, repo repository.Repository, now *time.Time) error {
+func (r *Resolver) updateCacheDB(ctx context.Context, gqlClient *api.GraphQLClient, repo repository.Repository, now *time.Time) error {
 	repoID := ToRepoID(repo)
 
 	if now == nil {
@@ -306,13 +401,21 @@ func (r *Resolver) updateCacheDB(ctx context.Context, repo repository.Repository
 		slog.String("ttl", r.cacheTTL.String()),
 	)
 
-	taghashMap, err := r.FetchTagAndOID(repo)
+	taghashMap, err := r.fetchTagAndOID(gqlClient, repo)
 	if err != nil {
 		return fmt.Errorf("failed to fetch tags and oids: %w", err)
 	}
 
+	return r.storeTags(ctx, repo, taghashMap, *now)
+}
+
+// storeTags stores the fetched tags of a repository to the cache database
+func (r *Resolver) storeTags(ctx context.Context, repo repository.Repository, taghashMap map[string]Hash, now time.Time) error {
+	repoID := ToRepoID(repo)
+
 	hashToTag := map[Hash]string{}
 	ttlMap := map[string]time.Time{}
+	tagListExpiredAt := now.Add(r.cacheTTL.GitTagTTL)
 
 	for tag, hash := range taghashMap {
 		if existTag, exist := hashToTag[hash]; exist {
@@ -321,13 +424,49 @@ func (r *Resolver) updateCacheDB(ctx context.Context, repo repository.Repository
 			// set a shorter TTL for alias tags because it is more likely to be updated
 			ttlMap[tag] = shortTTL
 			ttlMap[existTag] = shortTTL
+			tagListExpiredAt = shortTTL
 		} else {
 			ttlMap[tag] = now.Add(r.cacheTTL.GitTagTTL)
 			hashToTag[hash] = tag
 		}
 	}
 
-	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	var tagMoves []TagMove
+	var deletedTags []string
+
+	tags := make([]string, 0, len(taghashMap))
+	for tag := range taghashMap {
+		tags = append(tags, tag)
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		tagMoves, err = recordTagHistory(tx, repoID, taghashMap, now)
+		if err != nil {
+			return err
+		}
+
+		// reconcile the fetched tags with the cached tags to find the tags deleted upstream
+		deletedTags, err = markDeletedTags(tx, repoID, tags, now)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "repo_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"tag_list_expired_at": tagListExpiredAt,
+				"updated_at":          now,
+			}),
+		}).Create(&GitRepo{
+			RepoID:           repoID,
+			LastAccessedAt:   now,
+			TagListExpiredAt: tagListExpiredAt,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update the tag list expiry of %s: %w", repoID, result.Error)
+		}
+
 		for tag, hash := range taghashMap {
 			expiredAt, ok := ttlMap[tag]
 			if !ok {
@@ -340,6 +479,8 @@ func (r *Resolver) updateCacheDB(ctx context.Context, repo repository.Repository
 				BaseTag:    tag,
 				CommitHash: hash.CommitHash,
 				TagHash:    hash.TagHash,
+				Source:     SourceGraphQL,
+				FetchedAt:  now,
 				ExpiredAt:  expiredAt,
 			}
 			where := &GitTag{
@@ -354,6 +495,15 @@ func (r *Resolver) updateCacheDB(ctx context.Context, repo repository.Repository
 					return fmt.Errorf("failed to create a record: %w", result.Error)
 				}
 			}
+
+			// delete the records of the previous hashes if the tag is moved
+			result := tx.Unscoped().
+				Where(&GitTag{RepoID: repoID, Tag: tag}).
+				Where("(commit_hash <> ? OR tag_hash <> ?)", hash.CommitHash, hash.TagHash).
+				Delete(&GitTag{})
+			if result.Error != nil {
+				return fmt.Errorf("failed to delete stale records: %w", result.Error)
+			}
 		}
 
 		return nil
@@ -362,10 +512,52 @@ func (r *Resolver) updateCacheDB(ctx context.Context, repo repository.Repository
 		return fmt.Errorf("failed to update the database: %w", err)
 	}
 
-	if err := r.PruneCache(ctx, now); err != nil {
+	for _, tag := range deletedTags {
+		r.logger.Warn("a tag is deleted upstream", slog.String("repo", repoID), slog.String("tag", tag))
+	}
+
+	r.reportTagMoves(tagMoves)
+
+	if _, err := r.PruneCache(ctx, &now); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// reportTagMoves warns the moved tags and calls the OnTagMove callback
+func (r *Resolver) reportTagMoves(tagMoves []TagMove) {
+	for _, move := range tagMoves {
+		r.logger.Warn("a tag is moved",
+			slog.String("repo", move.RepoID),
+			slog.String("tag", move.Tag),
+			slog.String("from", move.From.CommitHash),
+			slog.String("to", move.To.CommitHash),
+		)
+
+		if r.onTagMove != nil {
+			r.onTagMove(move)
+		}
+	}
+}
+
+// Refresh re-fetches the tags of a repository bypassing the HTTP query cache,
+// and updates the cached records of the repository.
+// The cached records of the other repositories are not affected.
+func (r *Resolver) Refresh(ctx context.Context, repo repository.Repository) error {
+	repoID := ToRepoID(repo)
+	now := time.Now()
+
+	r.logger.Debug("refreshing a repository", slog.String("repo", repoID))
+
+	if err := r.touchRepo(ctx, repoID, now); err != nil {
 		return err
 	}
 
+	if err := r.updateCacheDB(ctx, r.refreshClient, repo, &now); err != nil {
+		return fmt.Errorf("failed to refresh %s: %w", repoID, err)
+	}
+
 	return nil
 }
 
@@ -423,9 +615,14 @@ func (r Resolver) ResolveFromTagContext(ctx context.Context, repo repository.Rep
 
 	r.logger.Debug("resolving a tag", slog.String("repo", repoID), slog.String("from", tag))
 
-	// try to fetch the record from the cache database at first
+	if err := r.touchRepo(ctx, repoID, now); err != nil {
+		return nil, err
+	}
+
+	// try to fetch the record from the cache database at first.
+	// the tags deleted upstream are re-fetched in case the tags are re-created.
 	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		result := tx.Where(&GitTag{RepoID: repoID, Tag: tag}).Where(whereNotExpired, now).First(&gitTag)
+		result := tx.Where(&GitTag{RepoID: repoID, Tag: tag}).Where(whereNotExpired, now).Where(whereUpstreamExist).First(&gitTag)
 		return result.Error
 	}, &sql.TxOptions{ReadOnly: true})
 	if err == nil {
@@ -435,16 +632,20 @@ func (r Resolver) ResolveFromTagContext(ctx context.Context, repo repository.Rep
 	}
 
 	// update the cache database if the record does not exist
-	if err := r.updateCacheDB(ctx, repo, &now); err != nil {
+	if err := r.updateCacheDB(ctx, r.gqlClient, repo, &now); err != nil {
 		return nil, fmt.Errorf("failed to update the cache database: %w", err)
 	}
 
 	// retry to fetch the record from the cache database after updating the cache
 	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		result := tx.Where(&GitTag{RepoID: repoID, Tag: tag}).Where(whereNotExpired, now).First(&gitTag)
+		result := tx.Where(&GitTag{RepoID: repoID, Tag: tag}).Where(whereNotExpired, now).Order(columnUpstreamDeletedAt).First(&gitTag)
 		return result.Error
 	}, &sql.TxOptions{ReadOnly: true})
 	if err == nil {
+		if gitTag.IsDeletedUpstream() {
+			return nil, &TagDeletedError{GitTag: gitTag}
+		}
+
 		return &gitTag, nil
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, fmt.Errorf("failed to select record: %w", err)
@@ -452,19 +653,32 @@ func (r Resolver) ResolveFromTagContext(ctx context.Context, repo repository.Rep
 
 	// resolve from the git object if the record does not exist
 
-	tagHash, err := r.resolveTagHashFromGitObj(ctx, repoID, tag)
-	if err != nil {
-		return nil, err
-	}
+	var tagHash, baseTag, commitHash string
 
-	baseTag, err := r.resolveBaseTagFromGitObj(ctx, repoID, tagHash)
-	if err != nil {
-		return nil, err
-	}
+	if d, ok := ParseDescribe(tag); ok {
+		// a describe-style string is verified rather than passed to rev-parse that ignores the base tag and the distance
+		commitHash, err = r.verifyDescribe(ctx, repoID, tag, *d)
+		if err != nil {
+			return nil, err
+		}
 
-	commitHash, err := r.resolveCommitHashFromGitObj(ctx, repoID, tag)
-	if err != nil {
-		return nil, err
+		tagHash = commitHash
+		baseTag = d.BaseTag
+	} else {
+		tagHash, err = r.resolveTagHashFromGitObj(ctx, repoID, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		baseTag, err = r.resolveBaseTagFromGitObj(ctx, repoID, tagHash)
+		if err != nil {
+			return nil, err
+		}
+
+		commitHash, err = r.resolveCommitHashFromGitObj(ctx, repoID, tag)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	newGitTag := &GitTag{
@@ -473,6 +687,8 @@ func (r Resolver) ResolveFromTagContext(ctx context.Context, repo repository.Rep
 		BaseTag:    baseTag,
 		TagHash:    tagHash,
 		CommitHash: commitHash,
+		Source:     SourceGit,
+		FetchedAt:  now,
 		ExpiredAt:  now.Add(r.cacheTTL.GitFileTTL),
 	}
 	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -504,7 +720,22 @@ func (r Resolver) ResolveFromHash(repo repository.Repository, hash string) ([]Gi
 	return r.ResolveFromHashContext(context.Background(), repo, hash)
 }
 
-// ResolveFromHashContext resolves a commit hash to tags with the specified context
+// filterDescribeCandidates filters the tags that exactly match a hash by the --match and the --exclude patterns
+// of the describe options. The records resolved by 'git describe' are kept as they are.
+func (r Resolver) filterDescribeCandidates(gitTags []GitTag) []GitTag {
+	filtered := make([]GitTag, 0, len(gitTags))
+
+	for _, gitTag := range gitTags {
+		if gitTag.Tag != gitTag.BaseTag || r.describeOptions.MatchTag(gitTag.Tag) {
+			filtered = append(filtered, gitTag)
+		}
+	}
+
+	return filtered
+}
+
+// ResolveFromHashContext resolves a commit hash to tags with the specified context.
+// If the hash does not match any tag, the nearest tag is resolved by 'git describe' with the DescribeOptions.
 func (r Resolver) ResolveFromHashContext(ctx context.Context, repo repository.Repository, hash string) ([]GitTag, error) {
 	if !IsSHA(hash) {
 		return nil, fmt.Errorf("invalid SHA: %s", hash)
@@ -516,13 +747,22 @@ func (r Resolver) ResolveFromHashContext(ctx context.Context, repo repository.Re
 	now := time.Now()
 	whereTagHash := &GitTag{RepoID: repoID, TagHash: hash}
 	whereCommitHash := &GitTag{RepoID: repoID, CommitHash: hash}
+	whereHash := r.db.Where(whereTagHash).Or(whereCommitHash)
+	describeKey := r.describeOptions.Key()
 
 	r.logger.Debug("resolving a hash", slog.String("repo", repoID), slog.String("from", hash))
 
+	if err := r.touchRepo(ctx, repoID, now); err != nil {
+		return nil, err
+	}
+
 	// try to fetch the record from the cache database at first
 	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		result := tx.Where(whereTagHash).Or(whereCommitHash).Where(whereNotExpired, now).Find(&gitTags)
+		result := tx.Where(whereHash).Where(whereNotExpired, now).Where(whereUpstreamExist).
+			Where(whereDescribeKey, describeKey).
+			Find(&gitTags)
 		if result.Error == nil {
+			gitTags = r.filterDescribeCandidates(gitTags)
 			if len(gitTags) > 0 {
 				return nil
 			}
@@ -539,14 +779,17 @@ func (r Resolver) ResolveFromHashContext(ctx context.Context, repo repository.Re
 	}
 
 	// update the cache database if the record does not exist
-	if err := r.updateCacheDB(ctx, repo, &now); err != nil {
+	if err := r.updateCacheDB(ctx, r.gqlClient, repo, &now); err != nil {
 		return nil, err
 	}
 
 	// retry to fetch the record from the cache database after updating the cache
 	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		result := tx.Where(whereTagHash).Or(whereCommitHash).Where(whereNotExpired, now).Find(&gitTags)
+		result := tx.Where(whereHash).Where(whereNotExpired, now).Where(whereUpstreamExist).
+			Where(whereDescribeKey, describeKey).
+			Find(&gitTags)
 		if result.Error == nil {
+			gitTags = r.filterDescribeCandidates(gitTags)
 			if len(gitTags) > 0 {
 				return nil
 			}
@@ -564,19 +807,26 @@ func (r Resolver) ResolveFromHashContext(ctx context.Context, repo repository.Re
 
 	// resolve from the git object if the record does not exist
 
-	tag, err := r.gdExecutor.RunGitDescribeContext(ctx, &gitdescribe.RepoCloneParams{
+	cloneParams := &gitdescribe.RepoCloneParams{
 		RepoID:   repoID,
 		CacheTTL: r.cacheTTL.GitFileTTL,
-	}, "--tags", hash)
-	if err != nil {
-		return nil, err
 	}
 
-	baseTag, err := r.resolveBaseTagFromGitObj(ctx, repoID, hash)
+	tag, err := r.gdExecutor.RunGitDescribeContext(ctx, cloneParams, append(r.describeOptions.args(), hash)...)
 	if err != nil {
 		return nil, err
 	}
 
+	// the output is the abbreviated hash if no tag is found with the --always option
+	var baseTag string
+	if !r.describeOptions.Always || !strings.HasPrefix(hash, tag) {
+		baseTag, err = r.gdExecutor.RunGitDescribeContext(ctx, cloneParams,
+			append(r.describeOptions.filterArgs(), "--abbrev=0", hash)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	tagHash, err := r.resolveTagHashFromGitObj(ctx, repoID, tag)
 	if err != nil {
 		return nil, err
@@ -588,19 +838,23 @@ func (r Resolver) ResolveFromHashContext(ctx context.Context, repo repository.Re
 	}
 
 	newGitTag := &GitTag{
-		RepoID:     repoID,
-		Tag:        tag,
-		BaseTag:    baseTag,
-		CommitHash: commitHash,
-		TagHash:    tagHash,
-		ExpiredAt:  now.Add(r.cacheTTL.GitFileTTL),
+		RepoID:      repoID,
+		Tag:         tag,
+		BaseTag:     baseTag,
+		CommitHash:  commitHash,
+		TagHash:     tagHash,
+		Source:      SourceGit,
+		DescribeKey: describeKey,
+		FetchedAt:   now,
+		ExpiredAt:   now.Add(r.cacheTTL.GitFileTTL),
 	}
 	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		where := &GitTag{
-			RepoID:     repoID,
-			Tag:        tag,
-			CommitHash: commitHash,
-			TagHash:    tagHash,
+			RepoID:      repoID,
+			Tag:         tag,
+			CommitHash:  commitHash,
+			TagHash:     tagHash,
+			DescribeKey: describeKey,
 		}
 		if tx.Model(&GitTag{}).Where(where).Updates(newGitTag).RowsAffected == 0 {
 			result := tx.Model(&GitTag{}).Create(newGitTag)
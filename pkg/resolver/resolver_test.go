@@ -21,6 +21,51 @@ var testLogger = slog.New(
 	}),
 )
 
+// newTestResolver creates a resolver that does not need to access GitHub
+// as long as the tests only work with the cache database.
+func newTestResolver(t *testing.T, params *Params) *Resolver {
+	t.Helper()
+	r := require.New(t)
+
+	gqlClient, err := api.NewGraphQLClient(api.ClientOptions{
+		AuthToken: "dummy",
+	})
+	r.NoError(err)
+
+	gdExecutor, err := gitdescribe.New(&gitdescribe.Params{
+		Logger:       testLogger,
+		CacheDirPath: t.TempDir(),
+	})
+	r.NoError(err)
+
+	if params == nil {
+		params = &Params{}
+	}
+	if params.Client == nil {
+		params.Client = gqlClient
+	}
+	if params.GitDescExecutor == nil {
+		params.GitDescExecutor = gdExecutor
+	}
+	params.Logger = testLogger
+	if params.CacheDirPath == "" {
+		params.CacheDirPath = t.TempDir()
+	}
+	if params.CacheTTL.GitTagTTL == 0 {
+		params.CacheTTL = *NewCacheTTL(time.Hour)
+	}
+
+	resolver, err := New(params)
+	r.NoError(err)
+	t.Cleanup(func() {
+		if resolver.db != nil {
+			resolver.Close()
+		}
+	})
+
+	return resolver
+}
+
 func TestIsSHA(t *testing.T) {
 	a := assert.New(t)
 
@@ -246,3 +291,63 @@ func TestResolver_ResolveFromHashContext(t *testing.T) {
 
 	a.NoError(resolver.Close())
 }
+
+func TestResolver_Refresh(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	repo := repository.Repository{
+		Owner: "actions",
+		Name:  "checkout",
+	}
+	otherRepo := repository.Repository{
+		Owner: "owner",
+		Name:  "other",
+	}
+
+	fake := newFakeGraphQL()
+	fake.setTags(ToRepoID(repo), map[string]Hash{
+		"v1.0.0": {
+			TagHash:    "0b496e91ec7ae4428c3ed2eeb4c3a40df431f2cc",
+			CommitHash: "0b496e91ec7ae4428c3ed2eeb4c3a40df431f2cc",
+		},
+		"v1.1.0": {
+			TagHash:    "ec3afacf7f605c9fc12c70bc1c9e1708ddb99eca",
+			CommitHash: "0b496e91ec7ae4428c3ed2eeb4c3a40df431f2cc",
+		},
+	})
+
+	resolver := newTestResolver(t, &Params{
+		Client:   fake.client(t),
+		CacheTTL: *NewCacheTTL(60 * time.Second),
+	})
+
+	r.NoError(resolver.db.Create(&GitTag{
+		RepoID:    ToRepoID(otherRepo),
+		Tag:       "v1.0.0",
+		ExpiredAt: time.Now().Add(time.Hour),
+	}).Error)
+
+	// a stale record of a moved tag
+	r.NoError(resolver.db.Create(&GitTag{
+		RepoID:     ToRepoID(repo),
+		Tag:        "v1.1.0",
+		TagHash:    "1111111111111111111111111111111111111111",
+		CommitHash: "1111111111111111111111111111111111111111",
+		ExpiredAt:  time.Now().Add(time.Hour),
+	}).Error)
+
+	r.NoError(resolver.Refresh(ctx, repo))
+	a.Equal(1, fake.queries(ToRepoID(repo)))
+
+	got, err := resolver.ResolveFromTagContext(ctx, repo, "v1.1.0")
+	r.NoError(err)
+	a.Equal("ec3afacf7f605c9fc12c70bc1c9e1708ddb99eca", got.TagHash)
+	a.Equal("0b496e91ec7ae4428c3ed2eeb4c3a40df431f2cc", got.CommitHash)
+
+	gitTags, err := resolver.ListCache(ctx, otherRepo)
+	r.NoError(err)
+	a.Len(gitTags, 1)
+	a.Zero(fake.queries(ToRepoID(otherRepo)))
+}
@@ -0,0 +1,206 @@
+package resolver
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var versionRegexp = regexp.MustCompile(`^[vV]?(0|[1-9]\d*)(?:\.(0|[1-9]\d*))?(?:\.(0|[1-9]\d*))?(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// Version represents a semantic version parsed from a tag.
+// Partial versions such as "v4" and "v4.1" are accepted to handle moving major/minor tags.
+// Path-prefixed tags of monorepos such as "sdk/go/v1.2.3" are accepted as well.
+type Version struct {
+	// Original is the tag that the version is parsed from
+	Original string
+
+	// Prefix is the path prefix of the tag including the trailing "/" (e.g. "sdk/go/").
+	// Empty if the tag does not have a path prefix.
+	Prefix string
+
+	Major uint64
+	Minor uint64
+	Patch uint64
+
+	// Prerelease is the pre-release identifiers without the leading "-"
+	Prerelease string
+
+	// Build is the build metadata without the leading "+"
+	Build string
+
+	// Parts is the number of the version core components (1 to 3)
+	Parts int
+}
+
+// ParseVersion parses a tag as a semantic version
+func ParseVersion(tag string) (*Version, error) {
+	prefix, versionStr := splitPathPrefix(tag)
+
+	matches := versionRegexp.FindStringSubmatch(versionStr)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid semantic version: %s", tag)
+	}
+
+	v := &Version{
+		Original:   tag,
+		Prefix:     prefix,
+		Prerelease: matches[4],
+		Build:      matches[5],
+	}
+
+	for i, dst := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		s := matches[i+1]
+		if s == "" {
+			break
+		}
+
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid semantic version: %s: %w", tag, err)
+		}
+
+		*dst = n
+		v.Parts++
+	}
+
+	return v, nil
+}
+
+// splitPathPrefix splits a tag into the path prefix including the trailing "/" and the rest
+func splitPathPrefix(tag string) (string, string) {
+	i := strings.LastIndex(tag, "/")
+
+	return tag[:i+1], tag[i+1:]
+}
+
+// IsPrerelease returns true if the version has pre-release identifiers
+func (v Version) IsPrerelease() bool {
+	return v.Prerelease != ""
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func comparePrerelease(a, b string) int {
+	// a version without pre-release identifiers has higher precedence
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, aErr := strconv.ParseUint(aIDs[i], 10, 64)
+		bNum, bErr := strconv.ParseUint(bIDs[i], 10, 64)
+
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = compareUint(aNum, bNum)
+		case aErr == nil:
+			// numeric identifiers have lower precedence than alphanumeric identifiers
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(aIDs[i], bIDs[i])
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return compareUint(uint64(len(aIDs)), uint64(len(bIDs)))
+}
+
+// Compare compares the precedence of the versions.
+// It returns -1, 0 or 1. Build metadata is ignored.
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// newerVersionFirst reports whether the tag of vi should precede the tag of vj in the order of newer versions first.
+// The tags that are not semantic versions (nil) follow the versions.
+func newerVersionFirst(vi, vj *Version) bool {
+	switch {
+	case vi == nil || vj == nil:
+		return vi != nil && vj == nil
+	case vi.Prefix != vj.Prefix:
+		return vi.Prefix < vj.Prefix
+	}
+
+	if c := vi.Compare(*vj); c != 0 {
+		return c > 0
+	}
+
+	// a more specific tag follows the moving tags of the same version (e.g. v4, v4.0, v4.0.0)
+	return vi.Parts < vj.Parts
+}
+
+// sortTagsBySemver sorts tags by the semantic versions in descending order.
+// The tags that are not semantic versions follow in the order of the names.
+func sortTagsBySemver(tags []string) {
+	versions := make(map[string]*Version, len(tags))
+	for _, tag := range tags {
+		if v, err := ParseVersion(tag); err == nil {
+			versions[tag] = v
+		}
+	}
+
+	sort.Strings(tags)
+	sort.SliceStable(tags, func(i, j int) bool {
+		return newerVersionFirst(versions[tags[i]], versions[tags[j]])
+	})
+}
+
+// mostSpecificVersionTag returns the semantic version tag that has the most version components.
+// If several tags have the same number of components, the highest version wins.
+// It returns an empty string if none of the tags is a semantic version.
+func mostSpecificVersionTag(tags []string) string {
+	var best *Version
+
+	for _, tag := range tags {
+		v, err := ParseVersion(tag)
+		if err != nil {
+			continue
+		}
+
+		if best == nil || v.Parts > best.Parts || (v.Parts == best.Parts && v.Compare(*best) > 0) {
+			best = v
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	return best.Original
+}
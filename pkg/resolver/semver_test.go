@@ -0,0 +1,94 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	testCases := []struct {
+		value   string
+		want    Version
+		wantErr bool
+	}{
+		{
+			value: "v4",
+			want:  Version{Original: "v4", Major: 4, Parts: 1},
+		},
+		{
+			value: "v4.1",
+			want:  Version{Original: "v4.1", Major: 4, Minor: 1, Parts: 2},
+		},
+		{
+			value: "1.2.3-rc.1+build.5",
+			want:  Version{Original: "1.2.3-rc.1+build.5", Major: 1, Minor: 2, Patch: 3, Prerelease: "rc.1", Build: "build.5", Parts: 3},
+		},
+		{
+			value: "sdk/go/v1.2.3",
+			want:  Version{Original: "sdk/go/v1.2.3", Prefix: "sdk/go/", Major: 1, Minor: 2, Patch: 3, Parts: 3},
+		},
+		{
+			value:   "latest",
+			wantErr: true,
+		},
+		{
+			value:   "v01.2.3",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := ParseVersion(tc.value)
+		if tc.wantErr {
+			a.Error(err, tc.value)
+			continue
+		}
+
+		r.NoError(err, tc.value)
+		a.Equal(tc.want, *got, tc.value)
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// in ascending order of precedence
+	versions := []string{
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0",
+		"v1.0.1",
+		"v1.10.0",
+		"v2.0.0",
+	}
+
+	for i := 0; i < len(versions)-1; i++ {
+		lower, err := ParseVersion(versions[i])
+		r.NoError(err)
+		higher, err := ParseVersion(versions[i+1])
+		r.NoError(err)
+
+		a.Equal(-1, lower.Compare(*higher), "%s < %s", versions[i], versions[i+1])
+		a.Equal(1, higher.Compare(*lower), "%s > %s", versions[i+1], versions[i])
+		a.Equal(0, lower.Compare(*lower), versions[i])
+	}
+}
+
+func TestMostSpecificVersionTag(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("v4.1.6", mostSpecificVersionTag([]string{"v4", "v4.1", "v4.1.6", "latest"}))
+	a.Equal("v4.1", mostSpecificVersionTag([]string{"v4.1", "v4"}))
+	a.Equal("v2.0.0", mostSpecificVersionTag([]string{"v1.9.9", "v2.0.0"}))
+	a.Empty(mostSpecificVersionTag([]string{"latest", "stable"}))
+}
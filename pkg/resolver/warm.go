@@ -0,0 +1,151 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	gitdescribe "github.com/thombashi/gh-git-describe/pkg/executor"
+)
+
+const defaultWarmConcurrency = 4
+
+// WarmParams represents the parameters for Warm
+type WarmParams struct {
+	// Concurrency is the maximum number of repositories fetched concurrently.
+	// Default is 4.
+	Concurrency int
+
+	// CloneRepo is a flag to prime the git clone cache as well.
+	// The clone cache is used when a tag or a hash is not found in the tag list.
+	CloneRepo bool
+
+	// Progress is called every time a repository is warmed.
+	// Calls are serialized.
+	Progress func(result WarmResult)
+}
+
+// WarmResult represents the result of warming the cache of a repository
+type WarmResult struct {
+	// RepoID is the GitHub repository ID formatted as "owner/name"
+	RepoID string `json:"repoId"`
+
+	// TagCount is the number of the fetched tags
+	TagCount int `json:"tagCount"`
+
+	// Cloned is true if the git clone cache is primed
+	Cloned bool `json:"cloned"`
+
+	// Err is the error occurred while warming the repository
+	Err error `json:"-"`
+}
+
+type fetchResult struct {
+	index      int
+	repo       repository.Repository
+	taghashMap map[string]Hash
+	result     WarmResult
+}
+
+// Warm populates the cache of the repositories in advance.
+// Tags are fetched concurrently while the cache database is updated sequentially.
+// Failures of the individual repositories are reported by WarmResult.Err,
+// and the returned results are in the same order as the repositories.
+func (r *Resolver) Warm(ctx context.Context, repos []repository.Repository, params *WarmParams) ([]WarmResult, error) {
+	if params == nil {
+		params = &WarmParams{}
+	}
+
+	concurrency := params.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultWarmConcurrency
+	}
+
+	results := make([]WarmResult, len(repos))
+	fetchResults := make(chan fetchResult)
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for i, repo := range repos {
+		wg.Add(1)
+
+		go func(i int, repo repository.Repository) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			fetchResults <- r.fetchForWarm(ctx, i, repo, params.CloneRepo)
+		}(i, repo)
+	}
+
+	go func() {
+		wg.Wait()
+		close(fetchResults)
+	}()
+
+	for fr := range fetchResults {
+		result := fr.result
+
+		if result.Err == nil {
+			now := time.Now()
+
+			if err := r.touchRepo(ctx, result.RepoID, now); err != nil {
+				result.Err = err
+			} else if err := r.storeTags(ctx, fr.repo, fr.taghashMap, now); err != nil {
+				result.Err = err
+			}
+		}
+
+		results[fr.index] = result
+
+		if params.Progress != nil {
+			params.Progress(result)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (r *Resolver) fetchForWarm(ctx context.Context, index int, repo repository.Repository, cloneRepo bool) fetchResult {
+	repoID := ToRepoID(repo)
+	fr := fetchResult{
+		index:  index,
+		repo:   repo,
+		result: WarmResult{RepoID: repoID},
+	}
+
+	r.logger.Debug("warming a repository", slog.String("repo", repoID))
+
+	taghashMap, err := r.fetchTagAndOID(r.gqlClient, repo)
+	if err != nil {
+		fr.result.Err = fmt.Errorf("failed to fetch tags and oids: %w", err)
+		return fr
+	}
+
+	fr.taghashMap = taghashMap
+	fr.result.TagCount = len(taghashMap)
+
+	if cloneRepo {
+		_, err := r.gdExecutor.RunRepoCloneContext(ctx, &gitdescribe.RepoCloneParams{
+			RepoID:   repoID,
+			CacheTTL: r.cacheTTL.GitFileTTL,
+		})
+		if err != nil {
+			fr.result.Err = fmt.Errorf("failed to clone the repository: %w", err)
+			return fr
+		}
+
+		fr.result.Cloned = true
+	}
+
+	return fr
+}
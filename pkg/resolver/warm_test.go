@@ -0,0 +1,57 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_Warm(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	repos := []repository.Repository{
+		{Owner: "actions", Name: "checkout"},
+		{Owner: "cli", Name: "cli"},
+		{Owner: "thombashi", Name: "not-exist-repository"},
+	}
+
+	fake := newFakeGraphQL()
+	fake.setTags(ToRepoID(repos[0]), map[string]Hash{
+		"v4.1.0": {CommitHash: strings.Repeat("1", 40), TagHash: strings.Repeat("1", 40)},
+		"v4.2.0": {CommitHash: strings.Repeat("2", 40), TagHash: strings.Repeat("2", 40)},
+	})
+	fake.setTags(ToRepoID(repos[1]), map[string]Hash{
+		"v2.0.0": {CommitHash: strings.Repeat("3", 40), TagHash: strings.Repeat("4", 40)},
+	})
+
+	resolver := newTestResolver(t, &Params{Client: fake.client(t), ClearCache: true})
+
+	var progressCount int
+	results, err := resolver.Warm(ctx, repos, &WarmParams{
+		Concurrency: 2,
+		Progress: func(result WarmResult) {
+			progressCount++
+		},
+	})
+	r.NoError(err)
+	r.Len(results, len(repos))
+	a.Equal(len(repos), progressCount)
+
+	for i, repo := range repos[:2] {
+		a.Equal(ToRepoID(repo), results[i].RepoID)
+		a.NoError(results[i].Err)
+		a.Positive(results[i].TagCount)
+		a.Equal(1, fake.queries(ToRepoID(repo)))
+
+		gitTags, err := resolver.ListCache(ctx, repo)
+		r.NoError(err)
+		a.Len(gitTags, results[i].TagCount)
+	}
+	a.Error(results[2].Err)
+}
@@ -0,0 +1,2 @@
+// package submodule provides a reader of the submodules of a local git repository and the commits they are pinned to.
+package submodule
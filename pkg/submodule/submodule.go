@@ -0,0 +1,242 @@
+package submodule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/thombashi/go-gitexec"
+)
+
+const (
+	gitmodulesFile = ".gitmodules"
+	gitlinkType    = "commit"
+)
+
+// Submodule represents a submodule of a git repository
+type Submodule struct {
+	// Name is the name of the submodule in .gitmodules
+	Name string
+
+	// Path is the path to the submodule from the root of the superproject
+	Path string
+
+	// URL is the URL of the submodule repository
+	URL string
+
+	// CommitHash is the commit hash that the gitlink of the submodule records.
+	// Empty if the superproject does not have the gitlink.
+	CommitHash string
+}
+
+// Repository returns the GitHub repository of the submodule URL
+func (s Submodule) Repository() (repository.Repository, error) {
+	repo, err := repository.Parse(s.URL)
+	if err != nil {
+		return repository.Repository{}, fmt.Errorf("failed to parse the submodule URL: %s: %w", s.URL, err)
+	}
+
+	if repo.Host != "github.com" {
+		return repository.Repository{}, fmt.Errorf("not a repository hosted on GitHub: %s", s.URL)
+	}
+
+	return repo, nil
+}
+
+// Params represents the parameters for New
+type Params struct {
+	// Dir is the path to the working tree of the superproject. Defaults to the current directory.
+	Dir string
+
+	// Logger is a Logger used by the reader
+	Logger *slog.Logger
+}
+
+// Reader reads the submodules of a local git repository
+type Reader struct {
+	executor gitexec.GitExecutor
+	dir      string
+	logger   *slog.Logger
+}
+
+// New creates a new reader
+func New(params *Params) (*Reader, error) {
+	logger := params.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	dir := params.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	executor, err := gitexec.New(&gitexec.Params{Logger: logger})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a git executor: %w", err)
+	}
+
+	return &Reader{
+		executor: executor,
+		dir:      dir,
+		logger:   logger,
+	}, nil
+}
+
+func (r Reader) runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	result, err := r.executor.RunGitContext(ctx, append([]string{"-C", dir}, args...)...)
+	if err != nil {
+		if result != nil {
+			return "", fmt.Errorf("failed to run git %s: %w: %s", args[0], err, strings.TrimSpace(result.Stderr.String()))
+		}
+
+		return "", fmt.Errorf("failed to run git %s: %w", args[0], err)
+	}
+
+	return result.Stdout.String(), nil
+}
+
+// parseConfigEntries parses the output of 'git config --null --get-regexp': the entries of "key\nvalue\x00"
+func parseConfigEntries(output string) map[string]string {
+	entries := map[string]string{}
+
+	for _, entry := range strings.Split(output, "\x00") {
+		key, value, ok := strings.Cut(entry, "\n")
+		if !ok {
+			continue
+		}
+
+		entries[key] = value
+	}
+
+	return entries
+}
+
+// parseSubmodules parses the entries of .gitmodules into the submodules in the order of the names
+func parseSubmodules(entries map[string]string) []Submodule {
+	names := map[string]bool{}
+	for key := range entries {
+		name, field, ok := cutSubmoduleKey(key)
+		if ok && field == "path" {
+			names[name] = true
+		}
+	}
+
+	submodules := make([]Submodule, 0, len(names))
+	for name := range names {
+		submodules = append(submodules, Submodule{
+			Name: name,
+			Path: entries["submodule."+name+".path"],
+			URL:  entries["submodule."+name+".url"],
+		})
+	}
+
+	sort.Slice(submodules, func(i, j int) bool {
+		return submodules[i].Name < submodules[j].Name
+	})
+
+	return submodules
+}
+
+// cutSubmoduleKey splits a key of .gitmodules (e.g. "submodule.<name>.path") into the name and the field.
+// The name may include dots.
+func cutSubmoduleKey(key string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(key, "submodule.")
+	if !ok {
+		return "", "", false
+	}
+
+	i := strings.LastIndex(rest, ".")
+	if i <= 0 {
+		return "", "", false
+	}
+
+	return rest[:i], rest[i+1:], true
+}
+
+// parseGitlinks parses the output of 'git ls-tree -z' into the commit hashes of the gitlinks per path
+func parseGitlinks(output string) map[string]string {
+	gitlinks := map[string]string{}
+
+	for _, entry := range strings.Split(output, "\x00") {
+		// <mode> SP <type> SP <object> TAB <path>
+		meta, path, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(meta)
+		if len(fields) != 3 || fields[1] != gitlinkType {
+			continue
+		}
+
+		gitlinks[path] = fields[2]
+	}
+
+	return gitlinks
+}
+
+// ListContext lists the submodules of .gitmodules with the commit hashes of the gitlinks at HEAD
+func (r Reader) ListContext(ctx context.Context) ([]Submodule, error) {
+	if _, err := os.Stat(filepath.Join(r.dir, gitmodulesFile)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Submodule{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to get the file info: %w", err)
+	}
+
+	output, err := r.runGit(ctx, r.dir, "config", "--file", gitmodulesFile, "--null", "--get-regexp", `^submodule\..*\.(path|url)$`)
+	if err != nil {
+		return nil, err
+	}
+
+	submodules := parseSubmodules(parseConfigEntries(output))
+	if len(submodules) == 0 {
+		return submodules, nil
+	}
+
+	args := []string{"ls-tree", "-z", "HEAD", "--"}
+	for _, submodule := range submodules {
+		args = append(args, submodule.Path)
+	}
+
+	output, err = r.runGit(ctx, r.dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	gitlinks := parseGitlinks(output)
+	for i := range submodules {
+		submodules[i].CommitHash = gitlinks[submodules[i].Path]
+	}
+
+	return submodules, nil
+}
+
+// CheckoutContext checks out a commit in the working tree of an initialized submodule.
+// The gitlink of the superproject is updated when the change is staged.
+func (r Reader) CheckoutContext(ctx context.Context, submodule Submodule, commitHash string) error {
+	dir := filepath.Join(r.dir, submodule.Path)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return fmt.Errorf("the submodule is not initialized: %s", submodule.Path)
+	}
+
+	r.logger.Debug("checking out a submodule", slog.String("path", submodule.Path), slog.String("commit", commitHash))
+
+	if _, err := r.runGit(ctx, dir, "fetch", "--quiet", "origin", commitHash); err != nil {
+		return err
+	}
+
+	if _, err := r.runGit(ctx, dir, "checkout", "--quiet", "--detach", commitHash); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package submodule
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "protocol.file.allow=always"}, args...)...)
+	cmd.Env = append(cmd.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+	)
+
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %s: %s", strings.Join(args, " "), output)
+
+	return strings.TrimSpace(string(output))
+}
+
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet", "--initial-branch=main")
+	runGit(t, dir, "commit", "--quiet", "--allow-empty", "-m", "initial")
+
+	return dir
+}
+
+func TestSubmodule_Repository(t *testing.T) {
+	a := assert.New(t)
+
+	for _, url := range []string{
+		"https://github.com/owner/lib.git",
+		"https://github.com/owner/lib",
+		"git@github.com:owner/lib.git",
+	} {
+		repo, err := Submodule{URL: url}.Repository()
+		a.NoError(err, url)
+		a.Equal("owner", repo.Owner, url)
+		a.Equal("lib", repo.Name, url)
+	}
+
+	_, err := Submodule{URL: "https://gitlab.com/owner/lib.git"}.Repository()
+	a.Error(err)
+}
+
+func TestCutSubmoduleKey(t *testing.T) {
+	a := assert.New(t)
+
+	name, field, ok := cutSubmoduleKey("submodule.third_party/lib.v2.path")
+	a.True(ok)
+	a.Equal("third_party/lib.v2", name)
+	a.Equal("path", field)
+
+	_, _, ok = cutSubmoduleKey("core.bare")
+	a.False(ok)
+}
+
+func TestReader_ListContext(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+	ctx := context.Background()
+
+	upstream := newTestRepo(t)
+	first := runGit(t, upstream, "rev-parse", "HEAD")
+	runGit(t, upstream, "commit", "--quiet", "--allow-empty", "-m", "second")
+	second := runGit(t, upstream, "rev-parse", "HEAD")
+
+	superproject := newTestRepo(t)
+
+	reader, err := New(&Params{Dir: superproject})
+	r.NoError(err)
+
+	submodules, err := reader.ListContext(ctx)
+	r.NoError(err)
+	a.Empty(submodules)
+
+	runGit(t, superproject, "submodule", "--quiet", "add", upstream, "vendor/lib")
+	runGit(t, filepath.Join(superproject, "vendor/lib"), "checkout", "--quiet", first)
+	runGit(t, superproject, "add", "vendor/lib")
+	runGit(t, superproject, "commit", "--quiet", "-m", "add a submodule")
+
+	submodules, err = reader.ListContext(ctx)
+	r.NoError(err)
+	r.Len(submodules, 1)
+	a.Equal(Submodule{Name: "vendor/lib", Path: "vendor/lib", URL: upstream, CommitHash: first}, submodules[0])
+
+	r.NoError(reader.CheckoutContext(ctx, submodules[0], second))
+	a.Equal(second, runGit(t, filepath.Join(superproject, "vendor/lib"), "rev-parse", "HEAD"))
+
+	a.Error(reader.CheckoutContext(ctx, Submodule{Path: "not-initialized"}, second))
+}
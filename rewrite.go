@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/pin"
+)
+
+func runRewriteCommand(args []string) {
+	var flags Flags
+	var rulesPath string
+	var dryRun, check bool
+
+	fs := newCommandFlagSet("rewrite", "[path...]", &flags)
+	fs.StringVar(
+		&rulesPath,
+		"rules",
+		".taghash-rules.yml",
+		"path to a YAML file of the rules to find the references in text files.",
+	)
+	fs.BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"print the changes as a unified diff without rewriting the files. --format is ignored.",
+	)
+	fs.BoolVar(
+		&check,
+		"check",
+		false,
+		fmt.Sprintf("report the references not pinned to commit hashes without resolving them, and exit with code %d if any is found.", exitCodeMutableRef),
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	rules, err := pin.LoadRules(rulesPath)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to load the rules"))
+
+	rewriter, err := pin.NewRewriter(&pin.RewriterParams{
+		Resolver: r,
+		Rules:    rules,
+		Logger:   logger,
+	})
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to create a rewriter"))
+
+	files, err := rewriter.FindFiles(paths)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to find files"))
+
+	if check {
+		runRewriteCheck(rewriter, files, flags)
+		return
+	}
+
+	ctx := context.Background()
+	changes := []pin.Change{}
+	var hasError bool
+
+	for _, file := range files {
+		result, err := rewriter.RewriteFile(ctx, file, dryRun)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to rewrite a file"))
+
+		for _, err := range result.Errors {
+			logger.Error("failed to rewrite a reference", slog.String("error", err.Error()))
+			hasError = true
+		}
+
+		if dryRun {
+			diff, err := result.Diff()
+			eoe.ExitOnError(err, eoeParams.WithMessage("failed to make a diff"))
+
+			fmt.Print(diff)
+			continue
+		}
+
+		changes = append(changes, result.Changes...)
+	}
+
+	if !dryRun {
+		err = printPinChanges(changes, flags)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to print changes"))
+	}
+
+	exitIfTagMoved(&flags)
+
+	if hasError {
+		os.Exit(1)
+	}
+}
+
+// runRewriteCheck reports the references not pinned to commit hashes in the files
+func runRewriteCheck(rewriter *pin.Rewriter, files []string, flags Flags) {
+	mutableRefs := []pin.Reference{}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		eoe.ExitOnError(err, eoe.NewParams().WithMessage("failed to read a file"))
+
+		for _, ref := range rewriter.ScanContent(file, content) {
+			if !ref.IsPinned() {
+				mutableRefs = append(mutableRefs, ref)
+			}
+		}
+	}
+
+	err := printMutableRefs(mutableRefs, flags)
+	eoe.ExitOnError(err, eoe.NewParams().WithMessage("failed to print references"))
+
+	if len(mutableRefs) > 0 {
+		os.Exit(exitCodeMutableRef)
+	}
+}
+
+func printMutableRefs(refs []pin.Reference, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple", "text":
+		for _, ref := range refs {
+			fmt.Printf("%s: not pinned to a commit hash\n", ref)
+		}
+
+	case "json":
+		bodies := make([]map[string]any, 0, len(refs))
+		for _, ref := range refs {
+			bodies = append(bodies, map[string]any{
+				"path": ref.Path,
+				"line": ref.Line,
+				"repo": ref.Action,
+				"ref":  ref.Ref,
+			})
+		}
+
+		return printJSON(bodies)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+	"github.com/thombashi/gh-taghash/pkg/submodule"
+)
+
+func runSubmodulesCommand(args []string) {
+	var flags Flags
+	var dir string
+	var checkoutLatest bool
+
+	fs := newCommandFlagSet("submodules", "", &flags)
+	fs.StringVar(
+		&dir,
+		"dir",
+		".",
+		"path to the working tree of the superproject.",
+	)
+	fs.BoolVar(
+		&checkoutLatest,
+		"checkout-latest",
+		false,
+		"check out the latest version tags in the initialized submodules. the git add command to stage the submodules is printed.",
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	reader, err := submodule.New(&submodule.Params{
+		Dir:    dir,
+		Logger: logger,
+	})
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to create a submodule reader"))
+
+	ctx := context.Background()
+
+	submodules, err := reader.ListContext(ctx)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to list the submodules"))
+
+	var hasError bool
+	var checkedOutPaths []string
+
+	for _, sub := range submodules {
+		repo, err := sub.Repository()
+		if err != nil {
+			logger.Debug("skip a submodule", slog.String("path", sub.Path), slog.String("reason", err.Error()))
+			continue
+		}
+
+		if sub.CommitHash == "" {
+			logger.Warn("the submodule does not have a gitlink", slog.String("path", sub.Path))
+			continue
+		}
+
+		gitTags, err := r.ResolveFromHashContext(ctx, repo, sub.CommitHash)
+		if err != nil {
+			logger.Error("failed to resolve a submodule commit",
+				slog.String("path", sub.Path),
+				slog.String("commit", sub.CommitHash),
+				slog.String("error", err.Error()))
+			hasError = true
+			continue
+		}
+
+		err = printSubmoduleTags(sub, gitTags, flags)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to print a result"))
+
+		if !checkoutLatest {
+			continue
+		}
+
+		latest, err := r.ResolveVersionQuery(ctx, repo, "latest", &resolver.VersionQueryParams{ExcludePrerelease: true})
+		if err != nil {
+			logger.Error("failed to resolve the latest version", slog.String("path", sub.Path), slog.String("error", err.Error()))
+			hasError = true
+			continue
+		}
+
+		if latest.CommitHash == sub.CommitHash {
+			continue
+		}
+
+		if err := reader.CheckoutContext(ctx, sub, latest.CommitHash); err != nil {
+			logger.Error("failed to check out the latest version", slog.String("path", sub.Path), slog.String("error", err.Error()))
+			hasError = true
+			continue
+		}
+
+		logger.Info("checked out the latest version",
+			slog.String("path", sub.Path),
+			slog.String("tag", latest.Tag),
+			slog.String("commit", latest.CommitHash))
+
+		checkedOutPaths = append(checkedOutPaths, sub.Path)
+	}
+
+	if len(checkedOutPaths) > 0 {
+		// the gitlinks of the superproject are updated only when the submodules are staged
+		logger.Info("stage the submodules to update the gitlinks",
+			slog.String("command", fmt.Sprintf("git -C %s add -- %s", dir, strings.Join(checkedOutPaths, " "))))
+	}
+
+	exitIfTagMoved(&flags)
+
+	if hasError {
+		os.Exit(1)
+	}
+}
+
+func printSubmoduleTags(sub submodule.Submodule, gitTags []resolver.GitTag, flags Flags) error {
+	tags := make([]string, 0, len(gitTags))
+	for _, gitTag := range gitTags {
+		tags = append(tags, gitTag.Tag)
+	}
+
+	switch flags.OutputFormat {
+	case "simple":
+		fmt.Printf("%s %s\n", sub.Path, strings.Join(tags, " "))
+
+	case "text":
+		for _, gitTag := range gitTags {
+			if gitTag.Tag == gitTag.BaseTag || gitTag.BaseTag == "" {
+				fmt.Printf("%s (%s): %s\n", sub.Path, sub.CommitHash, gitTag.Tag)
+				continue
+			}
+
+			fmt.Printf("%s (%s): %s (base: %s)\n", sub.Path, sub.CommitHash, gitTag.Tag, gitTag.BaseTag)
+		}
+
+	case "json":
+		tagInfos := make([]map[string]string, 0, len(gitTags))
+		for _, gitTag := range gitTags {
+			tagInfos = append(tagInfos, map[string]string{
+				"tag":     gitTag.Tag,
+				"baseTag": gitTag.BaseTag,
+			})
+		}
+
+		return printJSON(map[string]any{
+			"path":       sub.Path,
+			"url":        sub.URL,
+			"commitHash": sub.CommitHash,
+			"tags":       tagInfos,
+		})
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/pin"
+)
+
+// exitCodeVerifyFailed is the exit code when any reference fails the verification
+const exitCodeVerifyFailed = 6
+
+func runVerifyCommand(args []string) {
+	var flags Flags
+
+	fs := newCommandFlagSet("verify", "[path...]", &flags)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	files, err := pin.FindWorkflowFiles(paths)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to find workflow files"))
+
+	verifier, err := pin.NewVerifier(&pin.VerifierParams{
+		Resolver: r,
+		Logger:   logger,
+	})
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to create a verifier"))
+
+	ctx := context.Background()
+	allResults := []pin.Verification{}
+	var failed bool
+
+	for _, file := range files {
+		results, err := verifier.VerifyFile(ctx, file)
+		eoe.ExitOnError(err, eoeParams.WithMessage("failed to verify a file"))
+
+		for _, result := range results {
+			if !result.OK() {
+				failed = true
+			}
+			if result.Status == pin.VerifyStatusImpostor {
+				logger.Warn("an impostor commit is found",
+					slog.String("ref", result.Reference.String()),
+					slog.String("message", result.Message))
+			}
+		}
+
+		allResults = append(allResults, results...)
+	}
+
+	err = printVerifications(allResults, flags)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to print results"))
+
+	exitIfTagMoved(&flags)
+
+	if failed {
+		os.Exit(exitCodeVerifyFailed)
+	}
+}
+
+func printVerifications(results []pin.Verification, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		// print only the problems
+		for _, result := range results {
+			if !result.OK() {
+				fmt.Printf("%s: %s: %s\n", result.Reference, result.Status, result.Message)
+			}
+		}
+
+	case "text":
+		for _, result := range results {
+			if result.OK() {
+				fmt.Printf("%s: %s (%s)\n", result.Reference, result.Status, result.Version)
+				continue
+			}
+
+			fmt.Printf("%s: %s: %s\n", result.Reference, result.Status, result.Message)
+		}
+
+	case "json":
+		bodies := make([]map[string]any, 0, len(results))
+		for _, result := range results {
+			bodies = append(bodies, map[string]any{
+				"path":              result.Path,
+				"line":              result.Line,
+				"action":            result.Action,
+				"ref":               result.Ref,
+				"version":           result.Version,
+				"status":            string(result.Status),
+				"versionCommitHash": result.VersionCommitHash,
+				"pinnedTags":        result.PinnedTags,
+				"message":           result.Message,
+			})
+		}
+
+		return printJSON(bodies)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}
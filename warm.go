@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/thombashi/eoe"
+	"github.com/thombashi/gh-taghash/pkg/resolver"
+)
+
+// readRepoIDs reads repository IDs from a file.
+// Blank lines and lines starting with '#' are ignored.
+func readRepoIDs(filePath string) ([]string, error) {
+	var rd io.Reader
+
+	if filePath == "-" {
+		rd = os.Stdin
+	} else {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", filePath, err)
+		}
+		defer f.Close()
+
+		rd = f
+	}
+
+	var repoIDs []string
+
+	scanner := bufio.NewScanner(rd)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		repoIDs = append(repoIDs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	return repoIDs, nil
+}
+
+func runWarmCommand(args []string) {
+	var flags Flags
+	var filePath string
+	var concurrency int
+	var cloneRepo bool
+
+	fs := newCommandFlagSet("warm", "[owner/repo...]", &flags)
+	fs.StringVarP(
+		&filePath,
+		"file",
+		"f",
+		"",
+		"path to a file that lists repository IDs line by line. '-' means the standard input.",
+	)
+	fs.IntVar(
+		&concurrency,
+		"concurrency",
+		4,
+		"maximum number of repositories fetched concurrently",
+	)
+	fs.BoolVar(
+		&cloneRepo,
+		"clone",
+		false,
+		"prime the git clone cache as well",
+	)
+	eoe.ExitOnError(fs.Parse(args), eoe.NewParams().WithMessage("failed to parse flags"))
+	eoe.ExitOnError(flags.normalize(), eoe.NewParams().WithMessage("failed to parse flags"))
+
+	repoIDs := fs.Args()
+	if filePath != "" {
+		ids, err := readRepoIDs(filePath)
+		eoe.ExitOnError(err, eoe.NewParams().WithMessage("failed to read repository IDs"))
+
+		repoIDs = append(repoIDs, ids...)
+	}
+	if len(repoIDs) == 0 && flags.RepoID != "" {
+		repoIDs = []string{flags.RepoID}
+	}
+	if len(repoIDs) == 0 {
+		eoe.ExitOnError(errors.New("require at least one repository"), eoe.NewParams().WithMessage("failed to parse flags"))
+	}
+
+	logger, r := setup(&flags)
+	defer r.Close()
+
+	eoeParams := eoe.NewParams().WithLogger(logger)
+
+	repos := make([]repository.Repository, 0, len(repoIDs))
+	for _, repoID := range repoIDs {
+		repo, err := repository.Parse(repoID)
+		eoe.ExitOnError(err, eoeParams.WithMessage(fmt.Sprintf("failed to parse the repository ID: %s", repoID)))
+
+		repos = append(repos, repo)
+	}
+
+	var doneCount int
+	results, err := r.Warm(context.Background(), repos, &resolver.WarmParams{
+		Concurrency: concurrency,
+		CloneRepo:   cloneRepo,
+		Progress: func(result resolver.WarmResult) {
+			doneCount++
+			progress := fmt.Sprintf("%d/%d", doneCount, len(repos))
+
+			if result.Err != nil {
+				logger.Error("failed to warm a repository",
+					slog.String("progress", progress),
+					slog.String("repo", result.RepoID),
+					slog.Any("error", result.Err))
+				return
+			}
+
+			logger.Info("warmed a repository",
+				slog.String("progress", progress),
+				slog.String("repo", result.RepoID),
+				slog.Int("tags", result.TagCount))
+		},
+	})
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to warm the cache"))
+
+	err = printWarmResults(results, flags)
+	eoe.ExitOnError(err, eoeParams.WithMessage("failed to print the results"))
+
+	for _, result := range results {
+		if result.Err != nil {
+			os.Exit(1)
+		}
+	}
+
+	exitIfTagMoved(&flags)
+}
+
+func printWarmResults(results []resolver.WarmResult, flags Flags) error {
+	switch flags.OutputFormat {
+	case "simple":
+		for _, result := range results {
+			fmt.Printf("%s %d\n", result.RepoID, result.TagCount)
+		}
+
+	case "text":
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Printf("%s: error=%s\n", result.RepoID, result.Err)
+				continue
+			}
+
+			fmt.Printf("%s: tags=%d, cloned=%t\n", result.RepoID, result.TagCount, result.Cloned)
+		}
+
+	case "json":
+		bodies := make([]map[string]any, 0, len(results))
+		for _, result := range results {
+			body := map[string]any{
+				"repoId":   result.RepoID,
+				"tagCount": result.TagCount,
+				"cloned":   result.Cloned,
+			}
+			if result.Err != nil {
+				body["error"] = result.Err.Error()
+			}
+
+			bodies = append(bodies, body)
+		}
+
+		return printJSON(bodies)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s", flags.OutputFormat)
+	}
+
+	return nil
+}